@@ -0,0 +1,475 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gearsdatapacks/libra/interpreter"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+// The debugger only ever runs one thread
+const threadId = 1
+
+type dapMessage struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Command    string          `json:"command,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	RequestSeq int             `json:"request_seq,omitempty"`
+	Success    *bool           `json:"success,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Event      string          `json:"event,omitempty"`
+	Body       any             `json:"body,omitempty"`
+}
+
+type dapServer struct {
+	reader  *bufio.Reader
+	out     io.Writer
+	writeMu sync.Mutex
+	seq     int
+
+	policy      permissions.Policy
+	debugger    *Debugger
+	stopOnEntry bool
+
+	// Set by a disconnect or terminate request, once it has been responded to
+	done bool
+
+	// State of the current stop, only valid while the program is stopped
+	mu         sync.Mutex
+	frames     []Frame
+	references map[int]func() []Variable
+	resume     chan struct{}
+}
+
+// Serves the Debug Adapter Protocol, reading requests from in and writing responses and events to out.
+// Launched programs are given the permissions in policy
+func ServeDAP(policy permissions.Policy, in io.Reader, out io.Writer) error {
+	server := &dapServer{
+		policy: policy,
+		reader: bufio.NewReader(in),
+		out:    out,
+		resume: make(chan struct{}),
+	}
+
+	for {
+		request, err := server.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		server.handle(request)
+		if server.done {
+			return nil
+		}
+	}
+}
+
+func (s *dapServer) read() (*dapMessage, error) {
+	length := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("Invalid Content-Length header %q", line)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("Missing Content-Length header")
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, content); err != nil {
+		return nil, err
+	}
+
+	message := &dapMessage{}
+	err := json.Unmarshal(content, message)
+	return message, err
+}
+
+func (s *dapServer) send(message *dapMessage) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.seq++
+	message.Seq = s.seq
+	content, _ := json.Marshal(message)
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func (s *dapServer) respond(request *dapMessage, body any) {
+	success := true
+	s.send(&dapMessage{
+		Type:       "response",
+		RequestSeq: request.Seq,
+		Command:    request.Command,
+		Success:    &success,
+		Body:       body,
+	})
+}
+
+func (s *dapServer) fail(request *dapMessage, message string) {
+	success := false
+	s.send(&dapMessage{
+		Type:       "response",
+		RequestSeq: request.Seq,
+		Command:    request.Command,
+		Success:    &success,
+		Message:    message,
+	})
+}
+
+func (s *dapServer) event(event string, body any) {
+	s.send(&dapMessage{Type: "event", Event: event, Body: body})
+}
+
+// Sends everything the program prints to the client as output events
+type outputWriter struct {
+	server *dapServer
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w.server.event("output", map[string]any{"category": "stdout", "output": string(p)})
+	return len(p), nil
+}
+
+func (s *dapServer) handle(request *dapMessage) {
+	switch request.Command {
+	case "initialize":
+		s.respond(request, map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		})
+		s.event("initialized", nil)
+
+	case "launch":
+		s.launch(request)
+
+	case "setBreakpoints":
+		s.setBreakpoints(request)
+
+	case "setExceptionBreakpoints":
+		s.respond(request, map[string]any{"breakpoints": []any{}})
+
+	case "configurationDone":
+		if s.debugger == nil {
+			s.fail(request, "No program has been launched")
+			return
+		}
+		s.respond(request, nil)
+		go s.run()
+
+	case "threads":
+		s.respond(request, map[string]any{
+			"threads": []any{map[string]any{"id": threadId, "name": "main"}},
+		})
+
+	case "stackTrace":
+		s.stackTrace(request)
+
+	case "scopes":
+		s.scopes(request)
+
+	case "variables":
+		s.variables(request)
+
+	case "evaluate":
+		s.evaluate(request)
+
+	case "continue":
+		s.continueWith(request, s.debugger.Continue)
+	case "next":
+		s.continueWith(request, s.debugger.StepOver)
+	case "stepIn":
+		s.continueWith(request, s.debugger.StepIn)
+	case "stepOut":
+		s.continueWith(request, s.debugger.StepOut)
+
+	case "pause":
+		if s.debugger != nil {
+			s.debugger.Pause()
+		}
+		s.respond(request, nil)
+
+	case "disconnect", "terminate":
+		s.respond(request, nil)
+		s.done = true
+
+	default:
+		s.fail(request, fmt.Sprintf("Unsupported request %q", request.Command))
+	}
+}
+
+func (s *dapServer) launch(request *dapMessage) {
+	var arguments struct {
+		Program     string `json:"program"`
+		StopOnEntry bool   `json:"stopOnEntry"`
+	}
+	if err := json.Unmarshal(request.Arguments, &arguments); err != nil || arguments.Program == "" {
+		s.fail(request, "Expected a program to launch")
+		return
+	}
+
+	manager, err := modules.NewManager(arguments.Program, s.policy, symbols.New(), environment.New())
+	if err != nil {
+		s.fail(request, err.Error())
+		return
+	}
+	if err := typechecker.TypeCheck(manager); err != nil {
+		s.fail(request, err.Error())
+		return
+	}
+
+	s.debugger = New(manager, s.policy, s)
+	s.stopOnEntry = arguments.StopOnEntry
+	interpreter.SetIO(strings.NewReader(""), outputWriter{server: s})
+	s.respond(request, nil)
+}
+
+func (s *dapServer) run() {
+	exitCode := 0
+	if _, err := s.debugger.Run(s.stopOnEntry); err != nil {
+		s.event("output", map[string]any{"category": "stderr", "output": err.Error() + "\n"})
+		exitCode = 1
+	}
+	s.event("terminated", nil)
+	s.event("exited", map[string]any{"exitCode": exitCode})
+}
+
+func (s *dapServer) setBreakpoints(request *dapMessage) {
+	var arguments struct {
+		Source struct {
+			Path string `json:"path"`
+		} `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(request.Arguments, &arguments); err != nil || s.debugger == nil {
+		s.fail(request, "Breakpoints can only be set once a program has been launched")
+		return
+	}
+
+	lines := []int{}
+	for _, breakpoint := range arguments.Breakpoints {
+		lines = append(lines, breakpoint.Line)
+	}
+	verified := s.debugger.SetBreakpoints(arguments.Source.Path, lines)
+
+	breakpoints := []any{}
+	for i, line := range lines {
+		breakpoints = append(breakpoints, map[string]any{"verified": verified[i], "line": line})
+	}
+	s.respond(request, map[string]any{"breakpoints": breakpoints})
+}
+
+func (s *dapServer) Stopped(reason string, frames []Frame) {
+	s.mu.Lock()
+	s.frames = frames
+	s.references = map[int]func() []Variable{}
+	s.mu.Unlock()
+
+	s.event("stopped", map[string]any{
+		"reason":            reason,
+		"threadId":          threadId,
+		"allThreadsStopped": true,
+	})
+	<-s.resume
+}
+
+func (s *dapServer) continueWith(request *dapMessage, resume func()) {
+	s.mu.Lock()
+	stopped := s.frames != nil
+	s.frames = nil
+	s.references = nil
+	s.mu.Unlock()
+
+	if !stopped {
+		s.fail(request, "The program is not stopped")
+		return
+	}
+
+	resume()
+	s.respond(request, map[string]any{"allThreadsContinued": true})
+	s.resume <- struct{}{}
+}
+
+func (s *dapServer) frame(id int) (Frame, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 0 || id >= len(s.frames) {
+		return Frame{}, false
+	}
+	return s.frames[id], true
+}
+
+func (s *dapServer) stackTrace(request *dapMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stackFrames := []any{}
+	for i, frame := range s.frames {
+		stackFrames = append(stackFrames, map[string]any{
+			"id":     i,
+			"name":   frame.Name,
+			"source": map[string]any{"name": filepath.Base(frame.File), "path": frame.File},
+			"line":   frame.Line,
+			"column": frame.Column,
+		})
+	}
+	s.respond(request, map[string]any{"stackFrames": stackFrames, "totalFrames": len(stackFrames)})
+}
+
+// Registers a lazily expanded set of variables, returning its reference
+func (s *dapServer) reference(children func() []Variable) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.references == nil || children == nil {
+		return 0
+	}
+	id := len(s.references) + 1
+	s.references[id] = children
+	return id
+}
+
+func (s *dapServer) scopes(request *dapMessage) {
+	var arguments struct {
+		FrameId int `json:"frameId"`
+	}
+	json.Unmarshal(request.Arguments, &arguments)
+
+	frame, ok := s.frame(arguments.FrameId)
+	if !ok {
+		s.fail(request, "Invalid frame")
+		return
+	}
+
+	s.respond(request, map[string]any{"scopes": []any{
+		map[string]any{"name": "Locals", "variablesReference": s.reference(frame.Locals), "expensive": false},
+		map[string]any{"name": "Globals", "variablesReference": s.reference(frame.Globals), "expensive": false},
+	}})
+}
+
+func (s *dapServer) variables(request *dapMessage) {
+	var arguments struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	json.Unmarshal(request.Arguments, &arguments)
+
+	s.mu.Lock()
+	children, ok := s.references[arguments.VariablesReference]
+	s.mu.Unlock()
+	if !ok {
+		s.fail(request, "Invalid variables reference")
+		return
+	}
+
+	variables := []any{}
+	for _, variable := range children() {
+		variables = append(variables, map[string]any{
+			"name":               variable.Name,
+			"value":              variable.Value.ToString(),
+			"variablesReference": s.reference(childrenOf(variable.Value)),
+		})
+	}
+	s.respond(request, map[string]any{"variables": variables})
+}
+
+// Returns the members of a compound value, or nil if it has none
+func childrenOf(value values.RuntimeValue) func() []Variable {
+	switch value := value.(type) {
+	case *values.StructLiteral:
+		return func() []Variable {
+			variables := []Variable{}
+			for _, field := range value.Members {
+				variables = append(variables, Variable{Name: field.Name, Value: field.Value})
+			}
+			return variables
+		}
+
+	case *values.ListLiteral:
+		return func() []Variable { return indexedVariables(value.Elements) }
+
+	case *values.TupleValue:
+		return func() []Variable { return indexedVariables(value.Members) }
+
+	case *values.TupleStructValue:
+		return func() []Variable { return indexedVariables(value.Members) }
+
+	case *values.MapLiteral:
+		return func() []Variable {
+			variables := []Variable{}
+			for _, element := range value.Elements {
+				variables = append(variables, Variable{Name: element.Key.ToString(), Value: element.Value})
+			}
+			return variables
+		}
+
+	case *values.Pointer:
+		return func() []Variable { return []Variable{{Name: "*", Value: value.Target.Load()}} }
+	}
+	return nil
+}
+
+func indexedVariables(elements []values.RuntimeValue) []Variable {
+	variables := []Variable{}
+	for i, elem := range elements {
+		variables = append(variables, Variable{Name: strconv.Itoa(i), Value: elem})
+	}
+	return variables
+}
+
+func (s *dapServer) evaluate(request *dapMessage) {
+	var arguments struct {
+		Expression string `json:"expression"`
+		FrameId    int    `json:"frameId"`
+	}
+	json.Unmarshal(request.Arguments, &arguments)
+
+	frame, ok := s.frame(arguments.FrameId)
+	if !ok {
+		s.fail(request, "Expressions can only be evaluated while the program is stopped")
+		return
+	}
+
+	value, err := s.debugger.Evaluate(arguments.Expression, frame)
+	if err != nil {
+		s.fail(request, err.Error())
+		return
+	}
+
+	s.respond(request, map[string]any{
+		"result":             value.ToString(),
+		"variablesReference": s.reference(childrenOf(value)),
+	})
+}
@@ -0,0 +1,118 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter"
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+// A client talking to a DAP server over pipes
+type dapClient struct {
+	t        *testing.T
+	in       *io.PipeWriter
+	messages chan *dapMessage
+	seq      int
+	served   chan error
+}
+
+func startDAP(t *testing.T) *dapClient {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	client := &dapClient{
+		t:        t,
+		in:       inWriter,
+		messages: make(chan *dapMessage, 100),
+		served:   make(chan error, 1),
+	}
+
+	go func() {
+		client.served <- ServeDAP(permissions.Default(), inReader, outWriter)
+		outWriter.Close()
+	}()
+	// Reads everything the server sends, so it never blocks writing to the pipe
+	go func() {
+		reader := &dapServer{reader: bufio.NewReader(outReader)}
+		for {
+			message, err := reader.read()
+			if err != nil {
+				close(client.messages)
+				return
+			}
+			client.messages <- message
+		}
+	}()
+	t.Cleanup(func() { interpreter.SetIO(os.Stdin, os.Stdout) })
+	return client
+}
+
+func (c *dapClient) request(command string, arguments any) {
+	c.t.Helper()
+	c.seq++
+	args, _ := json.Marshal(arguments)
+	content, _ := json.Marshal(dapMessage{Seq: c.seq, Type: "request", Command: command, Arguments: args})
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(content), content); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// Reads messages until an event with the given name, returning everything read
+func (c *dapClient) readUntil(event string) []*dapMessage {
+	c.t.Helper()
+	messages := []*dapMessage{}
+	for message := range c.messages {
+		messages = append(messages, message)
+		if message.Type == "event" && message.Event == event {
+			return messages
+		}
+	}
+	c.t.Fatalf("the server stopped before sending %q", event)
+	return nil
+}
+
+func TestRuntimeErrorEndsSession(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "main.lb")
+	os.WriteFile(program, []byte(`const n = parse_int("x")!`), 0666)
+
+	client := startDAP(t)
+	client.request("initialize", nil)
+	client.request("launch", map[string]any{"program": program})
+	client.request("configurationDone", nil)
+	messages := client.readUntil("exited")
+
+	stderr := ""
+	terminated := false
+	for _, message := range messages {
+		if message.Type == "response" && !*message.Success {
+			t.Errorf("%s request failed: %s", message.Command, message.Message)
+		}
+		if message.Event == "terminated" {
+			terminated = true
+		}
+		if body, ok := message.Body.(map[string]any); ok && message.Event == "output" && body["category"] == "stderr" {
+			stderr += body["output"].(string)
+		}
+	}
+
+	if !strings.Contains(stderr, "parse_int") {
+		t.Errorf("expected the runtime error to be sent as stderr output, got %q", stderr)
+	}
+	if !terminated {
+		t.Error("expected a terminated event before exiting")
+	}
+	if exitCode := messages[len(messages)-1].Body.(map[string]any)["exitCode"]; exitCode != 1.0 {
+		t.Errorf("expected exit code 1, got %v", exitCode)
+	}
+
+	client.request("disconnect", nil)
+	if err := <-client.served; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,510 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gearsdatapacks/libra/interpreter"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/lexer"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+type stepMode int
+
+const (
+	RUN stepMode = iota
+	STEP_IN
+	STEP_OVER
+	STEP_OUT
+	PAUSE
+)
+
+// Frontend is the user-facing side of the debugger, such as the terminal UI or a DAP client
+type Frontend interface {
+	// Called from the interpreter whenever execution stops.
+	// Execution resumes once it returns, so it should block until the user
+	// has chosen how to continue
+	Stopped(reason string, frames []Frame)
+}
+
+type Frame struct {
+	Name    string
+	File    string
+	Line    int
+	Column  int
+	Env     *environment.Environment
+	Manager *modules.ModuleManager
+}
+
+type Variable struct {
+	Name  string
+	Value values.RuntimeValue
+}
+
+type activeStatement struct {
+	stmt    ast.Statement
+	manager *modules.ModuleManager
+}
+
+type Debugger struct {
+	mu          sync.Mutex
+	manager     *modules.ModuleManager
+	policy      permissions.Policy
+	frontend    Frontend
+	files       map[ast.Statement]string
+	lines       map[string]map[int]bool
+	breakpoints map[string]map[int]bool
+	mode        stepMode
+	stepDepth   int
+	stepLine    int
+	stepFile    string
+	lastLine    int
+	lastFile    string
+	// The statement currently executing at each call depth
+	active     []activeStatement
+	evaluating bool
+}
+
+func New(manager *modules.ModuleManager, policy permissions.Policy, frontend Frontend) *Debugger {
+	d := &Debugger{
+		manager:     manager,
+		policy:      policy,
+		frontend:    frontend,
+		files:       map[ast.Statement]string{},
+		lines:       map[string]map[int]bool{},
+		breakpoints: map[string]map[int]bool{},
+		mode:        RUN,
+	}
+	files := moduleFiles(manager, map[*modules.ModuleManager]bool{})
+	defaults := map[position]string{}
+	for _, file := range files {
+		recordDefaults(file.Ast.Body, normalisePath(file.Path), defaults)
+	}
+	for _, file := range files {
+		d.indexStatements(file.Ast.Body, normalisePath(file.Path), defaults)
+	}
+	return d
+}
+
+// The files of a module and every module it imports
+func moduleFiles(manager *modules.ModuleManager, seen map[*modules.ModuleManager]bool) []modules.Module {
+	if seen[manager] {
+		return nil
+	}
+	seen[manager] = true
+
+	files := []modules.Module{}
+	for _, mod := range manager.Imported {
+		files = append(files, moduleFiles(mod, seen)...)
+	}
+	return append(files, manager.Files...)
+}
+
+// Where a statement is in its file
+type position struct {
+	line, column int
+	source       string
+}
+
+func positionOf(stmt ast.Statement) position {
+	token := stmt.GetToken()
+	return position{line: token.Line, column: token.Column, source: stmt.String()}
+}
+
+// Impls get their own copy of the default methods they don't define, which can be in another file.
+// Records the file each statement of a default method is in, so the copies can be found there
+func recordDefaults(node any, file string, defaults map[position]string) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		if intDecl, ok := node.(*ast.InterfaceDeclaration); ok {
+			for _, member := range intDecl.Members {
+				for _, stmt := range member.Body {
+					defaults[positionOf(stmt)] = file
+				}
+			}
+		}
+		return true
+	})
+}
+
+// Records the file of every statement in part of a tree, and the lines it can stop on
+func (d *Debugger) indexStatements(node any, file string, defaults map[position]string) {
+	// Else branches and the if statements of if expressions are run without being visited themselves
+	unvisited := map[ast.Statement]bool{}
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		stmt, isStatement := node.(ast.Statement)
+		if isStatement {
+			d.files[stmt] = file
+			if isStoppable(stmt) && !unvisited[stmt] {
+				if d.lines[file] == nil {
+					d.lines[file] = map[int]bool{}
+				}
+				d.lines[file][stmt.GetToken().Line] = true
+			}
+		}
+
+		switch n := node.(type) {
+		case *ast.IfStatement:
+			if elseStatement, ok := n.Else.(ast.Statement); ok {
+				unvisited[elseStatement] = true
+			}
+		case *ast.IfExpression:
+			unvisited[n.Statement] = true
+		case *ast.ImplDeclaration:
+			for _, method := range n.Methods {
+				methodFile := file
+				if len(method.Body) != 0 {
+					if defaultFile, ok := defaults[positionOf(method.Body[0])]; ok {
+						methodFile = defaultFile
+					}
+				}
+				d.indexStatements(method.Body, methodFile, defaults)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// Declarations don't do anything at runtime, so there is no point stopping on them
+func isStoppable(stmt ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.FunctionDeclaration, *ast.StructDeclaration, *ast.TupleStructDeclaration,
+		*ast.UnitStructDeclaration, *ast.InterfaceDeclaration, *ast.TypeDeclaration,
+		*ast.ImportStatement, *ast.EnumDeclaration:
+		return false
+	default:
+		return true
+	}
+}
+
+func normalisePath(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.Clean(file)
+	}
+	return abs
+}
+
+// Sets the breakpoints for a file, replacing any existing ones.
+// Returns whether each breakpoint is on a line containing a statement
+func (d *Debugger) SetBreakpoints(file string, lines []int) []bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file = normalisePath(file)
+	d.breakpoints[file] = map[int]bool{}
+	verified := []bool{}
+
+	for _, line := range lines {
+		d.breakpoints[file][line] = true
+		verified = append(verified, d.lines[file][line])
+	}
+
+	return verified
+}
+
+func (d *Debugger) AddBreakpoint(file string, line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file = normalisePath(file)
+	if d.breakpoints[file] == nil {
+		d.breakpoints[file] = map[int]bool{}
+	}
+	d.breakpoints[file][line] = true
+	return d.lines[file][line]
+}
+
+func (d *Debugger) RemoveBreakpoint(file string, line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file = normalisePath(file)
+	if !d.breakpoints[file][line] {
+		return false
+	}
+	delete(d.breakpoints[file], line)
+	return true
+}
+
+type Breakpoint struct {
+	File string
+	Line int
+}
+
+func (d *Debugger) Breakpoints() []Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	breakpoints := []Breakpoint{}
+	for file, lines := range d.breakpoints {
+		for line := range lines {
+			breakpoints = append(breakpoints, Breakpoint{File: file, Line: line})
+		}
+	}
+
+	sort.Slice(breakpoints, func(i, j int) bool {
+		if breakpoints[i].File != breakpoints[j].File {
+			return breakpoints[i].File < breakpoints[j].File
+		}
+		return breakpoints[i].Line < breakpoints[j].Line
+	})
+	return breakpoints
+}
+
+// Runs the program to completion, stopping on breakpoints and steps.
+// If stopOnEntry is set, execution stops before the first statement.
+// A runtime error is returned rather than exiting, so the frontend can report it
+func (d *Debugger) Run(stopOnEntry bool) (values.RuntimeValue, error) {
+	if stopOnEntry {
+		d.mu.Lock()
+		d.mode = STEP_IN
+		d.stepLine = -1
+		d.mu.Unlock()
+	}
+
+	interpreter.SetTracer(d)
+	defer interpreter.SetTracer(nil)
+
+	return interpreter.EvaluateSandboxed(context.Background(), d.manager, interpreter.Limits{}, d.policy)
+}
+
+func (d *Debugger) Continue() { d.resume(RUN) }
+func (d *Debugger) StepIn()   { d.resume(STEP_IN) }
+func (d *Debugger) StepOver() { d.resume(STEP_OVER) }
+func (d *Debugger) StepOut()  { d.resume(STEP_OUT) }
+
+// Requests that execution stops at the next statement
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mode = PAUSE
+}
+
+func (d *Debugger) resume(mode stepMode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.mode = mode
+	d.stepDepth = len(d.active) - 1
+	d.stepLine = d.lastLine
+	d.stepFile = d.lastFile
+}
+
+func (d *Debugger) OnStatement(stmt ast.Statement, manager *modules.ModuleManager) {
+	// The frontend blocks until the user resumes, so the lock mustn't be held while it's stopped
+	reason, frames := d.enter(stmt, manager)
+	if reason != "" {
+		d.frontend.Stopped(reason, frames)
+	}
+}
+
+// Records the statement being executed, and works out whether to stop on it
+func (d *Debugger) enter(stmt ast.Statement, manager *modules.ModuleManager) (string, []Frame) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.evaluating {
+		return "", nil
+	}
+
+	depth := manager.Env.CallDepth()
+	if depth < len(d.active) {
+		d.active = d.active[:depth+1]
+	}
+	for len(d.active) <= depth {
+		d.active = append(d.active, activeStatement{})
+	}
+	d.active[depth] = activeStatement{stmt: stmt, manager: manager}
+
+	if !isStoppable(stmt) {
+		return "", nil
+	}
+
+	file := d.files[stmt]
+	line := stmt.GetToken().Line
+
+	reason := d.stopReason(file, line, depth)
+	d.lastFile = file
+	d.lastLine = line
+	if reason == "" {
+		return "", nil
+	}
+	return reason, d.frames()
+}
+
+// Must be called with d.mu held
+func (d *Debugger) stopReason(file string, line, depth int) string {
+	newLine := line != d.stepLine || file != d.stepFile
+
+	switch d.mode {
+	case PAUSE:
+		return "pause"
+	case STEP_IN:
+		if newLine || depth != d.stepDepth {
+			if d.stepLine == -1 {
+				return "entry"
+			}
+			return "step"
+		}
+	case STEP_OVER:
+		if depth < d.stepDepth || (depth == d.stepDepth && newLine) {
+			return "step"
+		}
+	case STEP_OUT:
+		if depth < d.stepDepth {
+			return "step"
+		}
+	}
+
+	enteredLine := line != d.lastLine || file != d.lastFile
+	if enteredLine && d.breakpoints[file][line] {
+		return "breakpoint"
+	}
+
+	return ""
+}
+
+// Must be called with d.mu held
+func (d *Debugger) frames() []Frame {
+	frames := []Frame{}
+	env := d.active[len(d.active)-1].manager.Env
+
+	for depth := len(d.active) - 1; depth >= 0; depth-- {
+		active := d.active[depth]
+		frame := Frame{
+			Name:    "main",
+			File:    d.files[active.stmt],
+			Line:    active.stmt.GetToken().Line,
+			Column:  active.stmt.GetToken().Column,
+			Env:     env,
+			Manager: active.manager,
+		}
+
+		// Point at the call itself, rather than the start of the statement containing it
+		if len(frames) != 0 {
+			if callSite := frames[len(frames)-1].Env.EnclosingFunction().CallSite; callSite != nil {
+				frame.Line = callSite.GetToken().Line
+				frame.Column = callSite.GetToken().Column
+			}
+		}
+
+		if function := env.EnclosingFunction(); function != nil {
+			frame.Name = function.Function
+			env = function.Caller
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// Returns the variables visible in the innermost function scope of the frame, including block scopes
+func (frame Frame) Locals() []Variable {
+	variables := map[string]values.RuntimeValue{}
+
+	for env := frame.Env; env != nil && !env.IsGlobal(); env = env.Parent {
+		for name, value := range env.Variables() {
+			if _, shadowed := variables[name]; !shadowed {
+				variables[name] = value
+			}
+		}
+		if env.EnclosingFunction() == env {
+			break
+		}
+	}
+
+	return sortVariables(variables)
+}
+
+func (frame Frame) Globals() []Variable {
+	return sortVariables(frame.Env.GlobalScope().Variables())
+}
+
+func sortVariables(variables map[string]values.RuntimeValue) []Variable {
+	result := []Variable{}
+	for name, value := range variables {
+		result = append(result, Variable{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Type checks and evaluates an expression in the scope of a frame
+func (d *Debugger) Evaluate(expression string, frame Frame) (result values.RuntimeValue, err error) {
+	lex := lexer.New([]byte(expression))
+	tokens, err := lex.Tokenise()
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := parser.New().Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(program.Body) != 1 {
+		return nil, fmt.Errorf("Expected a single expression")
+	}
+	exprStmt, ok := program.Body[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, fmt.Errorf("Expected an expression, got %s", program.Body[0].Type())
+	}
+
+	table := symbols.NewChild(frame.Manager.SymbolTable.GlobalScope(), symbols.GENERIC_SCOPE)
+	registered := map[string]bool{}
+	for env := frame.Env; env != nil && !env.IsGlobal(); env = env.Parent {
+		for name, value := range env.Variables() {
+			if registered[name] {
+				continue
+			}
+			registered[name] = true
+
+			var dataType types.ValidType = &types.Any{}
+			if value.Type() != nil {
+				dataType = value.Type()
+			}
+			table.RegisterSymbol(name, dataType, false)
+		}
+	}
+
+	manager := *frame.Manager
+	manager.SymbolTable = table
+	manager.Env = frame.Env
+
+	dataType := typechecker.TypeCheckExpression(exprStmt.Expression, &manager)
+	if typeErr, isErr := dataType.(*types.TypeError); isErr {
+		return nil, typeErr
+	}
+
+	d.setEvaluating(true)
+	defer func() {
+		d.setEvaluating(false)
+		if recovered := recover(); recovered != nil {
+			result = nil
+			err = fmt.Errorf("Failed to evaluate %q: %v", expression, recovered)
+		}
+	}()
+
+	return interpreter.EvaluateExpression(exprStmt.Expression, &manager), nil
+}
+
+// Statements run while evaluating an expression are ignored, so the debugger doesn't stop inside them
+func (d *Debugger) setEvaluating(evaluating bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.evaluating = evaluating
+}
@@ -0,0 +1,109 @@
+package debugger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/lexer"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+func TestMain(m *testing.M) {
+	registry.Register()
+	interpreter.Register()
+	os.Exit(m.Run())
+}
+
+// Records where the program stopped, resuming straight away
+type recorder struct {
+	debugger *Debugger
+	stops    []Frame
+}
+
+func (r *recorder) Stopped(reason string, frames []Frame) {
+	r.stops = append(r.stops, frames[0])
+	r.debugger.Continue()
+}
+
+// Runs a program in the debugger with breakpoints on the given lines, returning the frames it stopped in
+func debugSource(t *testing.T, source string, breakpoints ...int) []Frame {
+	t.Helper()
+
+	tokens, err := lexer.New([]byte(source)).Tokenise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := parser.New().Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := modules.NewDetatched(symbols.New(), environment.New())
+	manager.Files[0].Ast = program
+	if err := typechecker.TypeCheck(manager); err != nil {
+		t.Fatal(err)
+	}
+
+	interpreter.SetIO(strings.NewReader(""), &bytes.Buffer{})
+	defer interpreter.SetIO(os.Stdin, os.Stdout)
+
+	frontend := &recorder{}
+	frontend.debugger = New(manager, permissions.Default(), frontend)
+	frontend.debugger.SetBreakpoints(manager.Files[0].Path, breakpoints)
+	if _, err := frontend.debugger.Run(false); err != nil {
+		t.Fatal(err)
+	}
+	return frontend.stops
+}
+
+func stoppedLines(frames []Frame) []int {
+	lines := []int{}
+	for _, frame := range frames {
+		lines = append(lines, frame.Line)
+	}
+	return lines
+}
+
+func TestSpawnedTasksDoNotStop(t *testing.T) {
+	stops := debugSource(t, `fn work(n: int): int {
+		var x = n
+		x += 1
+		return x
+	}
+	const result = spawn work(1)
+	print(<-result)`, 3, 7)
+
+	if len(stops) != 1 || stops[0].Line != 7 || stops[0].Name != "main" {
+		t.Errorf("expected to stop only at line 7 in main, stopped at lines %v", stoppedLines(stops))
+	}
+}
+
+func TestBreakpointsInNestedBodies(t *testing.T) {
+	stops := debugSource(t, `struct C { n: int }
+	fn (C) get(): int {
+		const v = 1
+		return v
+	}
+	const r = {
+		const z = 2
+		z
+	}
+	const c = if r > 1 {
+		const w = 3
+		w
+	} else { 0 }
+	print(C { n: 1 }.get() + r + c)`, 3, 7, 11)
+
+	lines := stoppedLines(stops)
+	if len(lines) != 3 || lines[0] != 7 || lines[1] != 11 || lines[2] != 3 {
+		t.Errorf("expected to stop at lines 7, 11 and 3, stopped at lines %v", lines)
+	}
+}
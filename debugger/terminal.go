@@ -0,0 +1,261 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gearsdatapacks/libra/interpreter"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+const terminalHelp = `Commands:
+  c, continue            Continue until the next breakpoint
+  s, step                Step into the next statement, entering function calls
+  n, next                Step over function calls
+  o, out                 Step out of the current function
+  b, break [file:]line   Set a breakpoint
+  d, delete [file:]line  Remove a breakpoint
+  breakpoints            List breakpoints
+  l, locals              Show the local variables of the selected frame
+  g, globals             Show the global variables of the selected frame's module
+  bt, stack              Show the call stack
+  f, frame n             Select a frame from the call stack
+  p, print expr          Evaluate an expression in the selected frame
+  w, watch expr          Evaluate an expression every time execution stops
+  unwatch n              Remove a watch expression
+  q, quit                Stop debugging and exit`
+
+type terminal struct {
+	debugger *Debugger
+	reader   *bufio.Reader
+	out      io.Writer
+	watches  []string
+	sources  map[string][]string
+	frames   []Frame
+	selected int
+}
+
+// Debugs a program interactively from the terminal.
+// The program shares its input with the debugger, so prompt() reads from the same reader
+func RunTerminal(manager *modules.ModuleManager, policy permissions.Policy, in io.Reader, out io.Writer) {
+	term := &terminal{
+		reader:  bufio.NewReader(in),
+		out:     out,
+		sources: map[string][]string{},
+	}
+	term.debugger = New(manager, policy, term)
+	interpreter.SetIO(term.reader, out)
+
+	fmt.Fprintln(out, "Libra debugger. Type \"help\" for a list of commands.")
+	if _, err := term.debugger.Run(true); err != nil {
+		fmt.Fprintln(out, err)
+		fmt.Fprintln(out, "Program exited with an error")
+		return
+	}
+	fmt.Fprintln(out, "Program finished")
+}
+
+func (t *terminal) Stopped(reason string, frames []Frame) {
+	t.frames = frames
+	t.selected = 0
+	top := frames[0]
+
+	fmt.Fprintf(t.out, "Stopped (%s) in %s at %s:%d\n", reason, top.Name, displayPath(top.File), top.Line)
+	t.showSource(top)
+	t.showWatches()
+
+	for {
+		fmt.Fprint(t.out, "(debug) ")
+		line, err := t.reader.ReadString('\n')
+		if err != nil && line == "" {
+			os.Exit(0)
+		}
+
+		command, argument, _ := strings.Cut(strings.TrimSpace(line), " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "":
+			continue
+		case "h", "help":
+			fmt.Fprintln(t.out, terminalHelp)
+		case "c", "continue":
+			t.debugger.Continue()
+			return
+		case "s", "step":
+			t.debugger.StepIn()
+			return
+		case "n", "next":
+			t.debugger.StepOver()
+			return
+		case "o", "out":
+			t.debugger.StepOut()
+			return
+		case "b", "break":
+			t.setBreakpoint(argument)
+		case "d", "delete":
+			t.removeBreakpoint(argument)
+		case "breakpoints":
+			for _, breakpoint := range t.debugger.Breakpoints() {
+				fmt.Fprintf(t.out, "%s:%d\n", displayPath(breakpoint.File), breakpoint.Line)
+			}
+		case "l", "locals":
+			t.showVariables(t.frames[t.selected].Locals())
+		case "g", "globals":
+			t.showVariables(t.frames[t.selected].Globals())
+		case "bt", "stack":
+			t.showStack()
+		case "f", "frame":
+			t.selectFrame(argument)
+		case "p", "print":
+			t.print(argument)
+		case "w", "watch":
+			if argument == "" {
+				fmt.Fprintln(t.out, "Expected an expression to watch")
+				continue
+			}
+			t.watches = append(t.watches, argument)
+			t.showWatches()
+		case "unwatch":
+			t.unwatch(argument)
+		case "q", "quit":
+			os.Exit(0)
+		default:
+			fmt.Fprintf(t.out, "Unknown command %q. Type \"help\" for a list of commands.\n", command)
+		}
+	}
+}
+
+func displayPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	relative, err := filepath.Rel(wd, file)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return file
+	}
+	return relative
+}
+
+func (t *terminal) showSource(frame Frame) {
+	lines, ok := t.sources[frame.File]
+	if !ok {
+		code, err := os.ReadFile(frame.File)
+		if err == nil {
+			lines = strings.Split(string(code), "\n")
+		}
+		t.sources[frame.File] = lines
+	}
+
+	if frame.Line >= 1 && frame.Line <= len(lines) {
+		fmt.Fprintf(t.out, "%4d | %s\n", frame.Line, lines[frame.Line-1])
+	}
+}
+
+func (t *terminal) parseLocation(location string) (string, int, error) {
+	file := t.frames[t.selected].File
+	lineString := location
+
+	if index := strings.LastIndex(location, ":"); index != -1 {
+		file = location[:index]
+		lineString = location[index+1:]
+	}
+
+	line, err := strconv.Atoi(lineString)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("Invalid line number %q", lineString)
+	}
+	return file, line, nil
+}
+
+func (t *terminal) setBreakpoint(location string) {
+	file, line, err := t.parseLocation(location)
+	if err != nil {
+		fmt.Fprintln(t.out, err)
+		return
+	}
+
+	if !t.debugger.AddBreakpoint(file, line) {
+		fmt.Fprintf(t.out, "Warning: no statement on line %d of %s\n", line, displayPath(normalisePath(file)))
+	}
+	fmt.Fprintf(t.out, "Breakpoint set at %s:%d\n", displayPath(normalisePath(file)), line)
+}
+
+func (t *terminal) removeBreakpoint(location string) {
+	file, line, err := t.parseLocation(location)
+	if err != nil {
+		fmt.Fprintln(t.out, err)
+		return
+	}
+
+	if !t.debugger.RemoveBreakpoint(file, line) {
+		fmt.Fprintf(t.out, "No breakpoint at %s:%d\n", displayPath(normalisePath(file)), line)
+	}
+}
+
+func (t *terminal) showVariables(variables []Variable) {
+	if len(variables) == 0 {
+		fmt.Fprintln(t.out, "No variables")
+	}
+	for _, variable := range variables {
+		fmt.Fprintf(t.out, "%s = %s\n", variable.Name, variable.Value.ToString())
+	}
+}
+
+func (t *terminal) showStack() {
+	for i, frame := range t.frames {
+		marker := " "
+		if i == t.selected {
+			marker = "*"
+		}
+		fmt.Fprintf(t.out, "%s #%d %s at %s:%d\n", marker, i, frame.Name, displayPath(frame.File), frame.Line)
+	}
+}
+
+func (t *terminal) selectFrame(argument string) {
+	index, err := strconv.Atoi(argument)
+	if err != nil || index < 0 || index >= len(t.frames) {
+		fmt.Fprintf(t.out, "Invalid frame %q\n", argument)
+		return
+	}
+	t.selected = index
+	frame := t.frames[index]
+	fmt.Fprintf(t.out, "#%d %s at %s:%d\n", index, frame.Name, displayPath(frame.File), frame.Line)
+	t.showSource(frame)
+}
+
+func (t *terminal) print(expression string) {
+	value, err := t.debugger.Evaluate(expression, t.frames[t.selected])
+	if err != nil {
+		fmt.Fprintln(t.out, err)
+		return
+	}
+	fmt.Fprintln(t.out, value.ToString())
+}
+
+func (t *terminal) showWatches() {
+	for i, watch := range t.watches {
+		value, err := t.debugger.Evaluate(watch, t.frames[t.selected])
+		if err != nil {
+			fmt.Fprintf(t.out, "watch %d: %s = <%s>\n", i, watch, err)
+			continue
+		}
+		fmt.Fprintf(t.out, "watch %d: %s = %s\n", i, watch, value.ToString())
+	}
+}
+
+func (t *terminal) unwatch(argument string) {
+	index, err := strconv.Atoi(argument)
+	if err != nil || index < 0 || index >= len(t.watches) {
+		fmt.Fprintf(t.out, "Invalid watch %q\n", argument)
+		return
+	}
+	t.watches = append(t.watches[:index], t.watches[index+1:]...)
+}
@@ -2,7 +2,7 @@ package errors
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/gearsdatapacks/libra/parser/ast"
 )
@@ -25,6 +25,26 @@ func DevError(message string, errorNodes ...ast.Node) error {
 	return makeError("Error", message+"\nIf you're seeing this, some feature has not been implemented properly", errorNodes...)
 }
 
+// Kinds of error reported when a program exceeds the limits it is run with
+const (
+	STEP_LIMIT       = "StepLimitError"
+	CALL_DEPTH_LIMIT = "CallDepthError"
+	MEMORY_LIMIT     = "MemoryLimitError"
+	TIMEOUT          = "TimeoutError"
+)
+
+func LimitError(kind, message string, errorNodes ...ast.Node) error {
+	return makeError(kind, message, errorNodes...)
+}
+
+func PermissionError(message string, errorNodes ...ast.Node) error {
+	return makeError("PermissionError", message, errorNodes...)
+}
+
+func ImportError(message string, errorNodes ...ast.Node) error {
+	return makeError("ImportError", message, errorNodes...)
+}
+
 func makeError(prefix, message string, errorNodes ...ast.Node) error {
 	if len(errorNodes) == 0 {
 		return LanguageError{
@@ -44,7 +64,15 @@ func makeError(prefix, message string, errorNodes ...ast.Node) error {
 	}
 }
 
+// Raised by LogError, so that deferred calls can run as the interpreter unwinds
+type RuntimeError struct {
+	Message string
+}
+
+func (err RuntimeError) Error() string {
+	return err.Message
+}
+
 func LogError(values ...any) {
-	fmt.Println(values...)
-	os.Exit(1)
+	panic(RuntimeError{Message: strings.TrimSuffix(fmt.Sprintln(values...), "\n")})
 }
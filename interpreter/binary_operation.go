@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
 	"github.com/gearsdatapacks/libra/interpreter/values"
 	"github.com/gearsdatapacks/libra/modules"
 	"github.com/gearsdatapacks/libra/parser/ast"
@@ -36,14 +37,66 @@ func evaluateBinaryOperation(binOp *ast.BinaryOperation, manager *modules.Module
 		return evaluateExpression(binOp.Right, manager)
 	}
 
+	if binOp.Operator == "??" {
+		left := evaluateExpression(binOp.Left, manager)
+		if _, isNull := left.(*values.NullLiteral); !isNull {
+			return left
+		}
+
+		return evaluateExpression(binOp.Right, manager)
+	}
+
 	left := evaluateExpression(binOp.Left, manager)
 	right := evaluateExpression(binOp.Right, manager)
 
+	if binOp.Method != "" {
+		return evaluateOperatorMethod(binOp, left, right, manager)
+	}
+
+	// Sending can block, so it needs to know when the program has been stopped
+	if binOp.Operator == "<-" {
+		return send(left, right, manager.Env)
+	}
+
 	operation, ok := binaryOperators[binOp.Operator]
 
 	if !ok {
 		errors.LogError(errors.DevError(fmt.Sprintf("Operator %q does not exist", binOp.Operator), binOp))
 	}
 
-	return operation(left, right)
+	result := operation(left, right)
+
+	if _, isList := result.(*values.ListLiteral); isList && (binOp.Operator == "<<" || binOp.Operator == ">>") {
+		allocate(1, binOp, manager.Env)
+	} else if _, isString := result.(*values.StringLiteral); isString {
+		allocate(sizeOf(result), binOp, manager.Env)
+	}
+
+	return result
+}
+
+// Calls the method overloading an operator, as decided by the type checker
+func evaluateOperatorMethod(binOp *ast.BinaryOperation, left, right values.RuntimeValue, manager *modules.ModuleManager) values.RuntimeValue {
+	method := environment.GetMethod(binOp.Method, left.Type())
+	if method == nil {
+		errors.LogError(fmt.Sprintf("Operator %q is not defined for type %q, it has no method %q", binOp.Operator, left.Type(), binOp.Method))
+	}
+
+	bound := *method
+	bound.This = left
+	result := callFunction(&bound, []values.RuntimeValue{right}, binOp, manager)
+
+	switch binOp.Operator {
+	case "!=":
+		return values.MakeBoolean(!result.Truthy())
+	case "<":
+		return values.MakeBoolean(extractNumericValue(result) < 0)
+	case ">":
+		return values.MakeBoolean(extractNumericValue(result) > 0)
+	case "<=":
+		return values.MakeBoolean(extractNumericValue(result) <= 0)
+	case ">=":
+		return values.MakeBoolean(extractNumericValue(result) >= 0)
+	}
+	return result
 }
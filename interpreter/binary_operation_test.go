@@ -0,0 +1,36 @@
+package interpreter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+const vectorSource = `
+	struct V { x: int }
+	fn (V) add(o: V): V { return V { x: this.x + o.x } }
+	print((V { x: 1 } + V { x: 2 }).x)`
+
+func TestOperatorMethod(t *testing.T) {
+	expectOutput(t, vectorSource, "3\n")
+}
+
+func TestMissingOperatorMethodIsRuntimeError(t *testing.T) {
+	manager := load(t, vectorSource)
+	// A method the type checker would never choose, so there is nothing to call at runtime
+	ast.Inspect(manager.Files[0].Ast, func(node ast.Node) bool {
+		if binOp, ok := node.(*ast.BinaryOperation); ok && binOp.Method != "" {
+			binOp.Method = "sub"
+		}
+		return true
+	})
+
+	_, err := EvaluateSandboxed(context.Background(), manager, Limits{}, permissions.Default())
+	if _, ok := err.(errors.RuntimeError); !ok || !strings.Contains(err.Error(), `no method "sub"`) {
+		t.Errorf("expected a runtime error for the missing method, got %v", err)
+	}
+}
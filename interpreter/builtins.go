@@ -3,11 +3,18 @@ package interpreter
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gearsdatapacks/libra/interpreter/environment"
 	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
 func toPrintString(value values.RuntimeValue) string {
@@ -20,22 +27,62 @@ func toPrintString(value values.RuntimeValue) string {
 	return printStr
 }
 
+var reader = bufio.NewReader(os.Stdin)
+var output io.Writer = os.Stdout
+
+// Guards reader and output, so that concurrent prints and prompts don't interleave
+var ioMu sync.Mutex
+
+// Redirects the input and output used by builtins such as print and prompt
+func SetIO(in io.Reader, out io.Writer) {
+	ioMu.Lock()
+	defer ioMu.Unlock()
+
+	reader = bufio.NewReader(in)
+	output = out
+}
+
 func print(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
-	fmt.Println(toPrintString(args[0]))
+	ioMu.Lock()
+	defer ioMu.Unlock()
+
+	fmt.Fprintln(output, joinPrintStrings(args))
 
 	return values.MakeNull()
 }
 
 func printil(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
-	fmt.Print(toPrintString(args[0]))
+	ioMu.Lock()
+	defer ioMu.Unlock()
+
+	fmt.Fprint(output, joinPrintStrings(args))
 
 	return values.MakeNull()
 }
 
-var reader = bufio.NewReader(os.Stdin)
+func joinPrintStrings(args []values.RuntimeValue) string {
+	strs := []string{}
+	for _, arg := range args {
+		strs = append(strs, toPrintString(arg))
+	}
+	return strings.Join(strs, " ")
+}
+
+func permissionDenied(builtin, access, flag string) values.RuntimeValue {
+	return values.MakeError(fmt.Sprintf("%s: Permission denied: %s is not allowed (run with %s)", builtin, access, flag))
+}
 
 func prompt(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
-	fmt.Print(toPrintString(args[0]))
+	if !policyOf(env).CanPrompt() {
+		return permissionDenied("prompt", "reading from standard input", "--allow-prompt")
+	}
+
+	ioMu.Lock()
+	defer ioMu.Unlock()
+
+	if len(args) > 0 {
+		fmt.Fprint(output, toPrintString(args[0]))
+	}
 
 	result, _, _ := reader.ReadLine()
 
@@ -70,6 +117,10 @@ func parse_float(args []values.RuntimeValue, env *environment.Environment) value
 
 func read_file(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
 	fileName := args[0].(*values.StringLiteral).Value
+	if !policyOf(env).CanRead(fileName) {
+		return permissionDenied("read_file", fmt.Sprintf("reading %q", fileName), "--allow-read")
+	}
+
 	file, err := os.ReadFile(fileName)
 	if err != nil {
 		return values.MakeError(err.Error())
@@ -81,6 +132,10 @@ func read_file(args []values.RuntimeValue, env *environment.Environment) values.
 func write_file(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
 	fileName := args[0].(*values.StringLiteral).Value
 	contents := args[1].(*values.StringLiteral).Value
+	if !policyOf(env).CanWrite(fileName) {
+		return permissionDenied("write_file", fmt.Sprintf("writing to %q", fileName), "--allow-write")
+	}
+
 	err := os.WriteFile(fileName, []byte(contents), 0666)
 	if err != nil {
 		return values.MakeError(err.Error())
@@ -88,3 +143,160 @@ func write_file(args []values.RuntimeValue, env *environment.Environment) values
 
 	return values.MakeNull()
 }
+
+func get_env(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	name := args[0].(*values.StringLiteral).Value
+	if !policyOf(env).CanAccessEnv() {
+		return permissionDenied("get_env", "accessing environment variables", "--allow-env")
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return values.MakeError(fmt.Sprintf("get_env: Environment variable %q is not set", name))
+	}
+
+	return values.MakeString(value)
+}
+
+func run_command(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	command := args[0].(*values.StringLiteral).Value
+	if !policyOf(env).CanRun() {
+		return permissionDenied("run_command", fmt.Sprintf("running %q", command), "--allow-run")
+	}
+
+	commandArgs := []string{}
+	if len(args) > 1 {
+		for _, arg := range args[1].(*values.ListLiteral).Elements {
+			commandArgs = append(commandArgs, arg.(*values.StringLiteral).Value)
+		}
+	}
+
+	result, err := exec.Command(command, commandArgs...).Output()
+	if err != nil {
+		return values.MakeError(fmt.Sprintf("run_command: %s", err.Error()))
+	}
+
+	return values.MakeString(string(result))
+}
+
+func wrap_error(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	context := args[1].(*values.StringLiteral).Value
+	return values.WrapError(context+": "+errorMessage(args[0], env), args[0])
+}
+
+func unwrap_error(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	if err, ok := args[0].(*values.Error); ok && err.Cause != nil {
+		return err.Cause
+	}
+	return values.MakeNull()
+}
+
+// Calls a function passed to a builtin, casting the arguments to its parameter types
+func callCallback(callback values.RuntimeValue, env *environment.Environment, args ...values.RuntimeValue) values.RuntimeValue {
+	function := callback.(*values.FunctionValue)
+	for i, arg := range args {
+		args[i] = values.Cast(arg, function.Parameters[i].Type)
+	}
+	return callFunction(function, args, nil, &modules.ModuleManager{Env: env})
+}
+
+// Gets the elements of a list passed to a builtin, along with their type
+func callbackElements(list values.RuntimeValue) ([]values.RuntimeValue, types.ValidType) {
+	elemType, _ := types.ElemTypeOf(list.Type())
+	elements := []values.RuntimeValue{}
+	for _, elem := range list.(*values.ListLiteral).Elements {
+		elements = append(elements, values.Cast(elem, elemType))
+	}
+	return elements, elemType
+}
+
+func makeList(elements []values.RuntimeValue, elemType types.ValidType) values.RuntimeValue {
+	return &values.ListLiteral{
+		Elements:  elements,
+		BaseValue: values.BaseValue{DataType: &types.ListLiteral{ElemType: elemType}},
+	}
+}
+
+func map_list(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, _ := callbackElements(args[0])
+	returnType := args[1].Type().(*types.Function).ReturnType
+
+	results := []values.RuntimeValue{}
+	for _, elem := range elements {
+		results = append(results, values.Cast(callCallback(args[1], env, elem), returnType))
+	}
+	return makeList(results, returnType)
+}
+
+func filter(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, elemType := callbackElements(args[0])
+
+	results := []values.RuntimeValue{}
+	for _, elem := range elements {
+		if callCallback(args[1], env, elem).Truthy() {
+			results = append(results, elem)
+		}
+	}
+	return makeList(results, elemType)
+}
+
+func reduce(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, _ := callbackElements(args[0])
+
+	result := args[2]
+	for _, elem := range elements {
+		result = callCallback(args[1], env, result, elem)
+	}
+	return values.Cast(result, args[1].Type().(*types.Function).Parameters[0])
+}
+
+func sort_by(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, elemType := callbackElements(args[0])
+
+	keys := []values.RuntimeValue{}
+	for _, elem := range elements {
+		keys = append(keys, callCallback(args[1], env, elem))
+	}
+
+	indices := []int{}
+	for i := range elements {
+		indices = append(indices, i)
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return lessThan(keys[indices[i]], keys[indices[j]])
+	})
+
+	results := []values.RuntimeValue{}
+	for _, i := range indices {
+		results = append(results, elements[i])
+	}
+	return makeList(results, elemType)
+}
+
+// Compares the keys used by sort_by, which are either numbers or strings
+func lessThan(a, b values.RuntimeValue) bool {
+	if aString, isString := a.(*values.StringLiteral); isString {
+		return aString.Value < b.(*values.StringLiteral).Value
+	}
+	return extractNumericValue(a) < extractNumericValue(b)
+}
+
+func any_of(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, _ := callbackElements(args[0])
+	for _, elem := range elements {
+		if callCallback(args[1], env, elem).Truthy() {
+			return values.MakeBoolean(true)
+		}
+	}
+	return values.MakeBoolean(false)
+}
+
+func all_of(args []values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	elements, _ := callbackElements(args[0])
+	for _, elem := range elements {
+		if !callCallback(args[1], env, elem).Truthy() {
+			return values.MakeBoolean(false)
+		}
+	}
+	return values.MakeBoolean(true)
+}
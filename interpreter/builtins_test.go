@@ -0,0 +1,61 @@
+package interpreter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+func TestPromptIsAllowedByDefault(t *testing.T) {
+	expectOutput(t, `print(prompt("> "))`, "> \n")
+}
+
+func TestDeniedPromptReturnsError(t *testing.T) {
+	output, err := runSandboxed(t, `print(prompt("> "))`, Limits{}, permissions.Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "prompt: Permission denied") {
+		t.Errorf("expected prompt to return a permission error, got %q", output)
+	}
+}
+
+func TestEachEvaluationUsesItsOwnPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(file, []byte("data"), 0666)
+	source := `print(read_file("` + filepath.ToSlash(file) + `"))`
+
+	allowed, err := runSandboxed(t, source, Limits{}, permissions.AllowAll())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allowed != "data\n" {
+		t.Errorf("expected the file to be read, got %q", allowed)
+	}
+
+	denied, err := runSandboxed(t, source, Limits{}, permissions.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(denied, "read_file: Permission denied") {
+		t.Errorf("expected reading to be denied, got %q", denied)
+	}
+}
+
+func TestCollectionBuiltinsTakeListsHeldInVariables(t *testing.T) {
+	expectOutput(t, `
+		fn double(x: int): int { return x * 2 }
+		fn positive(x: int): boolean { return x > 0 }
+		var xs = [0, 2, 3]
+		print(map(xs, double))
+		print(filter(xs, positive))`, "[0, 4, 6]\n[2, 3]\n")
+}
+
+func TestDeclarationsShadowBuiltins(t *testing.T) {
+	expectOutput(t, `
+		fn map(x: int): int { return x + 1 }
+		print(map(1))`, "2\n")
+}
@@ -0,0 +1,243 @@
+package interpreter
+
+import (
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+)
+
+func evaluateSpawnExpression(spawn *ast.SpawnExpression, manager *modules.ModuleManager) values.RuntimeValue {
+	manager.EnterEnv(environment.NewTask(manager.Env))
+	call := prepareFunctionCall(spawn.Call, manager, true)
+	manager.ExitEnv()
+
+	// Buffered so the goroutine can finish even if the result is never received
+	channel := values.MakeChannel(1, spawn.GetType())
+
+	// The spawning task carries on changing the manager's scope, so the goroutine only uses the one it was spawned in
+	env := manager.Env
+	s := concurrentSandbox(env)
+	s.startTask()
+	go func() {
+		// The task only ends once any error it raised has been recorded
+		defer s.endTask()
+		defer s.recoverSpawned()
+		result := call()
+		s.communicate([]channelCase{{channel: channel, send: true, value: result}}, true, env)
+	}()
+
+	return channel
+}
+
+func evaluateChannelExpression(channel *ast.ChannelExpression, manager *modules.ModuleManager) values.RuntimeValue {
+	capacity := 0
+	if channel.Capacity != nil {
+		capacity = int(extractNumericValue(evaluateExpression(channel.Capacity, manager)))
+	}
+
+	return values.MakeChannel(capacity, channel.GetType())
+}
+
+// A send or receive, on its own or as a case of a select statement
+type channelCase struct {
+	channel *values.Channel
+	send    bool
+	value   values.RuntimeValue
+}
+
+func send(channel, value values.RuntimeValue, env *environment.Environment) values.RuntimeValue {
+	concurrentSandbox(env).communicate([]channelCase{{channel: channel.(*values.Channel), send: true, value: value}}, true, env)
+	return values.MakeNull()
+}
+
+func receive(channel values.RuntimeValue, _ bool, env *environment.Environment) values.RuntimeValue {
+	_, received := concurrentSandbox(env).communicate([]channelCase{{channel: channel.(*values.Channel)}}, true, env)
+	return received
+}
+
+// Tasks and channels are managed by the sandbox, so they can't be used outside of an evaluation,
+// for example in an expression evaluated with EvaluateExpression on its own
+func concurrentSandbox(env *environment.Environment) *sandbox {
+	s := sandboxOf(env)
+	if s == nil {
+		errors.LogError("Tasks and channels can only be used in a program run with Evaluate or EvaluateSandboxed")
+	}
+	return s
+}
+
+func (s *sandbox) startTask() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks++
+}
+
+func (s *sandbox) endTask() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks--
+	s.checkDeadlock()
+}
+
+// Stops the program once every goroutine running it is blocked on a channel, as none of them can carry on.
+// Must be called with the lock held
+func (s *sandbox) checkDeadlock() {
+	if s.tasks > 0 && s.waiting == s.tasks && s.err == nil {
+		s.err = errors.RuntimeError{Message: "Deadlock: every task is waiting on a channel"}
+		s.cancel()
+	}
+}
+
+// Carries out the first of the cases which is ready, returning which one it was and the value received.
+// If none are ready, it waits for one unless block is false, in which case it returns -1
+func (s *sandbox) communicate(cases []channelCase, block bool, env *environment.Environment) (int, values.RuntimeValue) {
+	s.mu.Lock()
+
+	if chosen, received := s.tryCases(cases); chosen != -1 || !block {
+		s.mu.Unlock()
+		return chosen, received
+	}
+
+	waiter := &values.ChannelWaiter{}
+	for i, c := range cases {
+		operation := &values.ChannelOperation{Waiter: waiter, Case: i, Value: c.value}
+		if c.send {
+			c.channel.Senders = append(c.channel.Senders, operation)
+		} else {
+			c.channel.Receivers = append(c.channel.Receivers, operation)
+		}
+	}
+
+	s.waiting++
+	s.checkDeadlock()
+	for !waiter.Done && s.ctx.Err() == nil {
+		s.wake.Wait()
+	}
+
+	if !waiter.Done {
+		// Stops its other operations from happening, now it's no longer waiting on them
+		waiter.Done = true
+		s.waiting--
+		s.mu.Unlock()
+		checkCancelled(env)
+		return -1, nil
+	}
+
+	s.mu.Unlock()
+	return waiter.Chosen, waiter.Received
+}
+
+func (s *sandbox) tryCases(cases []channelCase) (int, values.RuntimeValue) {
+	for i, c := range cases {
+		if c.send && s.trySend(c.channel, c.value) {
+			return i, nil
+		}
+		if !c.send {
+			if received, ok := s.tryReceive(c.channel); ok {
+				return i, received
+			}
+		}
+	}
+	return -1, nil
+}
+
+func (s *sandbox) trySend(channel *values.Channel, value values.RuntimeValue) bool {
+	if receiver := s.nextWaiting(&channel.Receivers); receiver != nil {
+		receiver.Waiter.Received = value
+		s.finish(receiver)
+		return true
+	}
+
+	if len(channel.Buffer) < channel.Capacity {
+		channel.Buffer = append(channel.Buffer, value)
+		return true
+	}
+	return false
+}
+
+func (s *sandbox) tryReceive(channel *values.Channel) (values.RuntimeValue, bool) {
+	if len(channel.Buffer) != 0 {
+		value := channel.Buffer[0]
+		channel.Buffer = channel.Buffer[1:]
+
+		// There's now room for the value of a blocked sender
+		if sender := s.nextWaiting(&channel.Senders); sender != nil {
+			channel.Buffer = append(channel.Buffer, sender.Value)
+			s.finish(sender)
+		}
+		return value, true
+	}
+
+	if sender := s.nextWaiting(&channel.Senders); sender != nil {
+		s.finish(sender)
+		return sender.Value, true
+	}
+	return nil, false
+}
+
+// Removes the first operation from a queue whose goroutine is still waiting,
+// dropping any from goroutines which have already carried on
+func (s *sandbox) nextWaiting(queue *[]*values.ChannelOperation) *values.ChannelOperation {
+	for len(*queue) != 0 {
+		operation := (*queue)[0]
+		*queue = (*queue)[1:]
+		if !operation.Waiter.Done {
+			return operation
+		}
+	}
+	return nil
+}
+
+// Wakes up the goroutine waiting on an operation which has just happened.
+// It no longer counts as waiting from this point, even before it has run
+func (s *sandbox) finish(operation *values.ChannelOperation) {
+	operation.Waiter.Done = true
+	operation.Waiter.Chosen = operation.Case
+	s.waiting--
+	s.wake.Broadcast()
+}
+
+func evaluateSelectStatement(selectStmt *ast.SelectStatement, manager *modules.ModuleManager) values.RuntimeValue {
+	cases := []channelCase{}
+
+	for _, selectCase := range selectStmt.Cases {
+		switch operation := selectCase.Operation.(type) {
+		case *ast.UnaryOperation:
+			channel := evaluateExpression(operation.Value, manager).(*values.Channel)
+			cases = append(cases, channelCase{channel: channel})
+
+		case *ast.BinaryOperation:
+			channel := evaluateExpression(operation.Left, manager).(*values.Channel)
+			value := evaluateExpression(operation.Right, manager)
+			cases = append(cases, channelCase{channel: channel, send: true, value: value})
+		}
+	}
+
+	chosen, received := concurrentSandbox(manager.Env).communicate(cases, selectStmt.Default == nil, manager.Env)
+
+	var body []ast.Statement
+	newScope := environment.NewChild(manager.Env, environment.GENERIC_SCOPE)
+
+	if chosen != -1 {
+		selectCase := selectStmt.Cases[chosen]
+		body = selectCase.Body
+
+		if selectCase.Variable != "" {
+			newScope.DeclareVariable(selectCase.Variable, received.Type(), received)
+		}
+	} else {
+		body = selectStmt.Default
+	}
+
+	manager.EnterEnv(newScope)
+	for _, statement := range body {
+		evaluate(statement, manager)
+		if manager.Env.HasReturned() {
+			break
+		}
+	}
+	manager.ExitEnv()
+
+	return values.MakeNull()
+}
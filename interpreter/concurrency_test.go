@@ -0,0 +1,80 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+// Run with -race: spawned tasks used to read the spawning task's scope while it changed,
+// and to share the variable name of a channel passed to several of them
+func TestSpawnWhileSpawningTaskChangesScope(t *testing.T) {
+	expectOutput(t, `
+		fn send(c: chan[int], n: int) { c <- n }
+		fn id(n: int): int { return n }
+		const c = chan[int](10)
+		var i = 0
+		while i < 10 {
+			spawn send(c, i)
+			const r = spawn id(i)
+			if true { const z = 1 }
+			i += 1
+		}
+		var total = 0
+		var j = 0
+		while j < 10 {
+			total += <-c
+			j += 1
+		}
+		print(total)`, "45\n")
+}
+
+func TestChannelsOutsideEvaluation(t *testing.T) {
+	manager := load(t, `
+		const c = chan[int](1)
+		c <- 1`)
+
+	defer func() {
+		if _, ok := recover().(errors.RuntimeError); !ok {
+			t.Error("expected a runtime error using a channel without a sandbox")
+		}
+	}()
+	evaluateProgram(manager)
+}
+
+func TestDeadlockIsRuntimeError(t *testing.T) {
+	sources := map[string]string{
+		"main task": `
+			const c = chan[int]()
+			print(<-c)`,
+		"every task": `
+			fn wait(c: chan[int]): int { return <-c }
+			const c = chan[int]()
+			const result = spawn wait(c)
+			print(<-result)`,
+	}
+
+	for name, source := range sources {
+		_, err := runSandboxed(t, source, Limits{}, permissions.Default())
+		if _, ok := err.(errors.RuntimeError); !ok || !strings.HasPrefix(err.Error(), "Deadlock") {
+			t.Errorf("%s: expected a deadlock to be reported, got %v", name, err)
+		}
+	}
+}
+
+func TestSpawnedArgumentsAreCopiedWhenEvaluated(t *testing.T) {
+	expectOutput(t, `
+		fn show(c: chan[int], xs: string[]): string[] {
+			const _ = <-c
+			return xs
+		}
+		const c = chan[int](1)
+		var xs: string[] = ["a"]
+		const result = spawn show(c, xs)
+		var i: int = 0
+		xs[i] = "b"
+		c <- 0
+		print(<-result)`, "[\"a\"]\n")
+}
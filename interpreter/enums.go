@@ -0,0 +1,53 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// The value of a member of an enum, or nil if the member holds data
+func enumMember(enum *types.Enum, name string) values.RuntimeValue {
+	if unitType, ok := enum.Types[name].DataType.(*types.Type).DataType.(*types.UnitStruct); ok {
+		return values.MakeUnitStruct(name, unitType)
+	}
+	return nil
+}
+
+// Gives the values of the associated functions every enum has
+func registerEnumFunctions(enum *types.Enum) {
+	environment.AddMethod(&values.FunctionValue{
+		Name:       "values",
+		Parameters: []values.Parameter{},
+		BaseValue:  values.BaseValue{DataType: types.AssociatedFunction(enum, "values", 0)},
+		Native: func([]values.RuntimeValue) values.RuntimeValue {
+			members := []values.RuntimeValue{}
+			for _, name := range enum.Order {
+				if member := enumMember(enum, name); member != nil {
+					members = append(members, member)
+				}
+			}
+			return &values.ListLiteral{
+				Elements:  members,
+				BaseValue: values.BaseValue{DataType: &types.ListLiteral{ElemType: enum}},
+			}
+		},
+	})
+
+	environment.AddMethod(&values.FunctionValue{
+		Name:       "from",
+		Parameters: []values.Parameter{{Name: "name", Type: &types.StringLiteral{}}},
+		BaseValue:  values.BaseValue{DataType: types.AssociatedFunction(enum, "from", 0)},
+		Native: func(args []values.RuntimeValue) values.RuntimeValue {
+			name := args[0].(*values.StringLiteral).Value
+			if _, exists := enum.Types[name]; exists {
+				if member := enumMember(enum, name); member != nil {
+					return member
+				}
+			}
+			return values.MakeError(fmt.Sprintf("%s.from: No member named %q", enum.Name, name))
+		},
+	})
+}
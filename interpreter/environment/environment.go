@@ -2,9 +2,11 @@ package environment
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/gearsdatapacks/libra/errors"
 	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/parser/ast"
 	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
@@ -17,12 +19,35 @@ const (
 )
 
 type Environment struct {
-	Parent    *Environment
+	Parent *Environment
+	// Guards variables, as spawned functions can share an environment with their caller
+	mu        sync.RWMutex
 	variables map[string]values.RuntimeValue
 	// types       map[string]types.ValidType
 	kind        scopeKind
 	ReturnValue values.RuntimeValue
 	Exports     map[string]values.RuntimeValue
+	// Only set for function scopes, to keep track of the call stack
+	Function string
+	CallSite ast.Node
+	Caller   *Environment
+	depth    int
+	// Calls registered by defer statements, to run when the function exits
+	deferred []func() values.RuntimeValue
+	// Set when the function returns by calling another, which is made once it has exited
+	TailCall *TailCall
+	// Set on the scope a task started by spawn is made from
+	spawned bool
+	// The interpreter's state for the evaluation the scope is part of, such as its limits.
+	// It's set on the global scopes when an evaluation starts, and passed on to every scope made during it
+	Sandbox any
+}
+
+// A call whose function and arguments have been evaluated, but which hasn't been made yet
+type TailCall struct {
+	Function *values.FunctionValue
+	Args     []values.RuntimeValue
+	CallSite ast.Node
 }
 
 func New() *Environment {
@@ -42,9 +67,28 @@ func NewChild(parent *Environment, kind scopeKind) *Environment {
 		variables: map[string]values.RuntimeValue{},
 		// types:       parent.types,
 		kind:    kind,
+		Sandbox: parent.Sandbox,
 	}
 }
 
+// Makes the scope a spawned task is started from, which the call's arguments are evaluated in
+func NewTask(parent *Environment) *Environment {
+	env := NewChild(parent, GENERIC_SCOPE)
+	env.spawned = true
+	return env
+}
+
+func NewFunction(parent *Environment, caller *Environment, name string, callSite ast.Node) *Environment {
+	env := NewChild(parent, FUNCTION_SCOPE)
+	env.Function = name
+	env.CallSite = callSite
+	env.Caller = caller
+	env.depth = caller.CallDepth() + 1
+	// A function can be declared in an earlier evaluation than the one calling it, such as a previous line of the REPL
+	env.Sandbox = caller.Sandbox
+	return env
+}
+
 func (env *Environment) DeclareVariable(name string, varType types.ValidType, value values.RuntimeValue) values.RuntimeValue {
 	return env.setVariable(name, varType, value)
 }
@@ -56,27 +100,46 @@ func (env *Environment) AssignVariable(name string, varType types.ValidType, val
 }
 
 func (env *Environment) setVariable(name string, varType types.ValidType, value values.RuntimeValue) values.RuntimeValue {
+	shared := value
 	if castable, ok := value.(values.AutoCastable); ok {
 		value = castable.AutoCast(varType)
 	} else {
 		value = value.Copy()
 	}
 
+	// Values shared by reference, such as channels, can be bound to several variables by different goroutines at once
+	if value != shared {
+		value.SetVarname(name)
+	}
+	env.mu.Lock()
 	env.variables[name] = value
-	value.SetVarname(name)
+	env.mu.Unlock()
 	return value
 }
 
+func (env *Environment) lookup(name string) (values.RuntimeValue, bool) {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	value, ok := env.variables[name]
+	return value, ok
+}
+
 func (env *Environment) GetVariable(name string) values.RuntimeValue {
 	declaredEnvironment := env.resolve(name)
 	if declaredEnvironment == nil {
 		errors.LogError(errors.DevError(fmt.Sprintf("Cannot find variable %q, it does not exist", name)))
 	}
-	return declaredEnvironment.variables[name]
+	value, _ := declaredEnvironment.lookup(name)
+	return value
+}
+
+// Finds the scope a variable was declared in, or nil if it doesn't exist
+func (env *Environment) DeclaredIn(varName string) *Environment {
+	return env.resolve(varName)
 }
 
 func (env *Environment) resolve(varName string) *Environment {
-	if _, ok := env.variables[varName]; ok {
+	if _, ok := env.lookup(varName); ok {
 		return env
 	}
 
@@ -91,6 +154,79 @@ func (env *Environment) isFunctionScope() bool {
 	return env.kind == FUNCTION_SCOPE
 }
 
+// Like FindFunctionScope, but returns nil outside of a function instead of erroring
+func (env *Environment) EnclosingFunction() *Environment {
+	if env.isFunctionScope() {
+		return env
+	}
+	if env.Parent == nil {
+		return nil
+	}
+	return env.Parent.EnclosingFunction()
+}
+
+// Registers a call to run when the function exits
+func (env *Environment) Defer(call func() values.RuntimeValue) {
+	env.deferred = append(env.deferred, call)
+}
+
+func (env *Environment) HasDeferred() bool {
+	return len(env.deferred) != 0
+}
+
+// Runs the deferred calls of a function scope, most recently deferred first
+func (env *Environment) RunDeferred() {
+	for len(env.deferred) > 0 {
+		call := env.deferred[len(env.deferred)-1]
+		env.deferred = env.deferred[:len(env.deferred)-1]
+		call()
+	}
+}
+
+// Whether the enclosing function has already returned, so the rest of a block should be skipped
+func (env *Environment) HasReturned() bool {
+	scope := env.EnclosingFunction()
+	return scope != nil && scope.ReturnValue != nil
+}
+
+func (env *Environment) CallDepth() int {
+	scope := env.EnclosingFunction()
+	if scope == nil {
+		return 0
+	}
+	return scope.depth
+}
+
+// Whether the scope is run by a task started with spawn, rather than the program's main task
+func (env *Environment) InSpawnedTask() bool {
+	for scope := env; scope != nil; {
+		if scope.spawned {
+			return true
+		}
+		if scope.isFunctionScope() {
+			scope = scope.Caller
+		} else {
+			scope = scope.Parent
+		}
+	}
+	return false
+}
+
+func (env *Environment) IsGlobal() bool {
+	return env.Parent == nil
+}
+
+func (env *Environment) Variables() map[string]values.RuntimeValue {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+
+	variables := map[string]values.RuntimeValue{}
+	for name, value := range env.variables {
+		variables[name] = value
+	}
+	return variables
+}
+
 func (env *Environment) FindFunctionScope() *Environment {
 	if env.isFunctionScope() {
 		return env
@@ -114,30 +250,33 @@ func (env *Environment) Exists(name string) bool {
 	return env.resolve(name) != nil
 }
 
-var methods = map[string][]*values.FunctionValue{}
+// The values of methods and associated functions, found through the type checker's method tables
+var methods = map[*types.Function]*values.FunctionValue{}
+var methodsMu sync.RWMutex
 
 func GetMethod(name string, methodOf types.ValidType) *values.FunctionValue {
-	overloads, ok := methods[name]
-	if !ok {
-		return nil
-	}
+	return getFunctionValue(types.Method(methodOf, name, 0))
+}
 
-	for _, overload := range overloads {
-		if overload.Type().(*types.Function).MethodOf.Valid(methodOf) {
-			return overload
-		}
+func GetAssociatedFunction(name string, of types.ValidType) *values.FunctionValue {
+	return getFunctionValue(types.AssociatedFunction(of, name, 0))
+}
+
+func getFunctionValue(fnType *types.Function) *values.FunctionValue {
+	if fnType == nil {
+		return nil
 	}
 
-	return nil
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+	return methods[fnType]
 }
 
-func AddMethod(name string, method *values.FunctionValue) {
-	overloads, ok := methods[name]
-	if !ok {
-		methods[name] = []*values.FunctionValue{method}
-	}
-	overloads = append(overloads, method)
-	methods[name] = overloads
+func AddMethod(method *values.FunctionValue) {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+
+	methods[method.Type().(*types.Function)] = method
 }
 
 func (env *Environment) GlobalScope() *Environment {
@@ -13,6 +13,8 @@ import (
 )
 
 func evaluateExpression(expr ast.Expression, manager *modules.ModuleManager) values.RuntimeValue {
+	step(expr, manager.Env)
+
 	switch expression := expr.(type) {
 	case *ast.IntegerLiteral:
 		return values.MakeUntypedNumber(float64(expression.Value), false)
@@ -21,6 +23,7 @@ func evaluateExpression(expr ast.Expression, manager *modules.ModuleManager) val
 		return values.MakeUntypedNumber(expression.Value, true)
 
 	case *ast.StringLiteral:
+		allocate(len(expression.Value), expression, manager.Env)
 		return values.MakeString(expression.Value)
 
 	case *ast.BooleanLiteral:
@@ -53,9 +56,27 @@ func evaluateExpression(expr ast.Expression, manager *modules.ModuleManager) val
 	case *ast.FunctionCall:
 		return evaluateFunctionCall(expression, manager)
 
+	case *ast.SpawnExpression:
+		return evaluateSpawnExpression(expression, manager)
+
+	case *ast.ChannelExpression:
+		return evaluateChannelExpression(expression, manager)
+
 	case *ast.IndexExpression:
 		return evaluateIndexExpression(expression, manager)
 
+	case *ast.SliceExpression:
+		return evaluateSliceExpression(expression, manager)
+
+	case *ast.ListComprehension:
+		return evaluateListComprehension(expression, manager)
+
+	case *ast.IfExpression:
+		return evaluateIfStatement(expression.Statement, manager)
+
+	case *ast.BlockExpression:
+		return evaluateBlock(expression.Body, manager)
+
 	case *ast.MemberExpression:
 		return evaluateMemberExpression(*expression, manager)
 
@@ -87,12 +108,25 @@ func evaluateAssignmentExpression(assignment *ast.AssignmentExpression, manager
 			Left:     assignment.Assignee,
 			Right:    assignment.Value,
 			Operator: operator,
+			Method:   assignment.Method,
+			BaseNode: ast.BaseNode{Token: assignment.Token},
 		}, manager)
 	} else {
 		value = evaluateExpression(assignment.Value, manager)
 	}
 
-	switch assignee := assignment.Assignee.(type) {
+	switch assignment.Assignee.(type) {
+	case *ast.TupleExpression, *ast.ListLiteral:
+		// The whole value is evaluated first, so `(a, b) = (b, a)` swaps the values
+		assignDestructured(assignment.Assignee, value, manager)
+		return value
+	}
+
+	return assign(assignment.Assignee, value, manager)
+}
+
+func assign(assignee ast.Expression, value values.RuntimeValue, manager *modules.ModuleManager) values.RuntimeValue {
+	switch assignee := assignee.(type) {
 	case *ast.Identifier:
 		leftValue := manager.Env.GetVariable(assignee.Symbol)
 		return manager.Env.AssignVariable(assignee.Symbol, leftValue.Type(), value)
@@ -100,65 +134,230 @@ func evaluateAssignmentExpression(assignment *ast.AssignmentExpression, manager
 	case *ast.IndexExpression:
 		leftValue := evaluateExpression(assignee.Left, manager)
 		indexValue := evaluateExpression(assignee.Index, manager)
-		return leftValue.SetIndex(indexValue, value)
+		return allocateGrowth(leftValue, assignee, manager.Env, func() values.RuntimeValue {
+			return leftValue.SetIndex(indexValue, value)
+		})
 
 	case *ast.MemberExpression:
 		leftValue := evaluateExpression(assignee.Left, manager)
 		return leftValue.SetMember(assignee.Member, value)
+
+	case *ast.UnaryOperation:
+		pointer := evaluateExpression(assignee.Value, manager).(*values.Pointer)
+		// A pointer to a missing map entry adds it when written to
+		if index, ok := pointer.Target.(indexLocation); ok {
+			return allocateGrowth(index.parent.Load(), assignee, manager.Env, func() values.RuntimeValue {
+				return index.Store(value)
+			})
+		}
+		return pointer.Target.Store(value)
 	}
 
 	return value
 }
 
 func evaluateFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManager) values.RuntimeValue {
+	return prepareFunctionCall(call, manager, false)()
+}
+
+// Evaluates the function being called and its arguments, returning a function which makes the call.
+// This allows spawn and defer to evaluate the arguments before the call is made, in which case
+// later is set and the arguments are copied, so changes made in the meantime aren't seen by the call
+func prepareFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManager, later bool) func() values.RuntimeValue {
 	if ident, ok := call.Left.(*ast.Identifier); ok {
 		if structType, isStruct := manager.SymbolTable.GetType(ident.Symbol).(*types.TupleStruct); isStruct {
-			return evaluateTupleStructExpression(structType, call, manager)
+			value := evaluateTupleStructExpression(structType, call, manager)
+			return func() values.RuntimeValue { return value }
+		}
+
+		if ident.Symbol == "typeof" && isBuiltin(ident.Symbol, manager) {
+			value := evaluateTypeof(call, manager)
+			return func() values.RuntimeValue { return value }
 		}
 
-		if builtin, ok := builtins[ident.Symbol]; ok {
+		if builtin, ok := builtins[ident.Symbol]; ok && isBuiltin(ident.Symbol, manager) {
 			args := []values.RuntimeValue{}
 
+			// Builtins can't take named arguments, so any optional parameters are simply left off,
+			// and variadic arguments are passed on the end
 			for _, arg := range call.Args {
-				args = append(args, evaluateExpression(arg, manager))
+				args = append(args, evaluateArgument(arg, manager)...)
+			}
+			if later {
+				args = copyValues(args)
 			}
 
-			return builtin(args, manager.Env)
+			env := manager.Env
+			return func() values.RuntimeValue {
+				result := builtin(args, env)
+				allocate(sizeOf(result), call, env)
+				return result
+			}
 		}
 	}
 
 	ty := typechecker.TypeCheckTypeExpression(call.Left, manager)
 	if structType, isStruct := ty.(*types.TupleStruct); isStruct {
-		return evaluateTupleStructExpression(structType, call, manager)
+		value := evaluateTupleStructExpression(structType, call, manager)
+		return func() values.RuntimeValue { return value }
+	}
+
+	function, args := evaluateCall(call, manager)
+	if later {
+		args = copyValues(args)
+	}
+	caller := *manager
+	return func() values.RuntimeValue {
+		return callFunction(function, args, call, &caller)
+	}
+}
+
+// Copies a list of arguments, leaving out any which weren't passed
+func copyValues(vals []values.RuntimeValue) []values.RuntimeValue {
+	copied := make([]values.RuntimeValue, len(vals))
+	for i, value := range vals {
+		if value != nil {
+			copied[i] = value.Copy()
+		}
+	}
+	return copied
+}
+
+// Whether a name refers to a builtin function, rather than a declaration shadowing it
+func isBuiltin(name string, manager *modules.ModuleManager) bool {
+	_, isBuiltin := builtins[name]
+	return (isBuiltin || name == "typeof") && manager.Env.DeclaredIn(name) == nil
+}
+
+// Whether a call is to a function value, rather than a builtin or a tuple struct
+func callsFunctionValue(call *ast.FunctionCall, manager *modules.ModuleManager) bool {
+	if ident, ok := call.Left.(*ast.Identifier); ok {
+		if _, isStruct := manager.SymbolTable.GetType(ident.Symbol).(*types.TupleStruct); isStruct {
+			return false
+		}
+		if isBuiltin(ident.Symbol, manager) {
+			return false
+		}
 	}
 
+	_, isStruct := typechecker.TypeCheckTypeExpression(call.Left, manager).(*types.TupleStruct)
+	return !isStruct
+}
+
+// Evaluates the function value being called and the arguments to pass it
+func evaluateCall(call *ast.FunctionCall, manager *modules.ModuleManager) (*values.FunctionValue, []values.RuntimeValue) {
 	function := evaluateExpression(call.Left, manager).(*values.FunctionValue)
+	args := make([]values.RuntimeValue, len(function.Parameters))
+	fixed := len(function.Parameters)
+	if fixed != 0 && function.Parameters[fixed-1].Variadic {
+		fixed--
+	}
+
+	for i, arg := range call.Args {
+		if i < fixed {
+			args[i] = evaluateExpression(arg, manager)
+			continue
+		}
+
+		if args[fixed] == nil {
+			args[fixed] = &values.ListLiteral{
+				Elements:  []values.RuntimeValue{},
+				BaseValue: values.BaseValue{DataType: function.Parameters[fixed].Type},
+			}
+		}
+		rest := args[fixed].(*values.ListLiteral)
+		elemType := function.Parameters[fixed].Type.(*types.ListLiteral).ElemType
+		for _, value := range evaluateArgument(arg, manager) {
+			rest.Elements = append(rest.Elements, values.Cast(value, elemType))
+		}
+	}
+	if fixed < len(args) && args[fixed] != nil {
+		allocate(len(args[fixed].(*values.ListLiteral).Elements), call, manager.Env)
+	}
+
+	for _, arg := range call.NamedArgs {
+		for i, param := range function.Parameters {
+			if param.Name == arg.Name {
+				args[i] = evaluateExpression(arg.Value, manager)
+			}
+		}
+	}
+
+	return function, args
+}
+
+// Evaluates an argument, spreading it out into its elements if needed
+func evaluateArgument(arg ast.Expression, manager *modules.ModuleManager) []values.RuntimeValue {
+	if spread, ok := arg.(*ast.SpreadExpression); ok {
+		list := evaluateExpression(spread.Value, manager).(*values.ListLiteral)
+		return list.Elements
+	}
+	return []values.RuntimeValue{evaluateExpression(arg, manager)}
+}
+
+func callFunction(function *values.FunctionValue, args []values.RuntimeValue, callSite ast.Node, caller *modules.ModuleManager) values.RuntimeValue {
+	for {
+		if function.Native != nil {
+			// Native functions, such as json.decode, build their results from scratch
+			result := function.Native(args)
+			allocate(nestedSizeOf(result), callSite, caller.Env)
+			return result
+		}
+
+		result, tailCall := runFunction(function, args, callSite, caller)
+		if tailCall == nil {
+			return result
+		}
+		// A call in tail position replaces the function that made it,
+		// so neither the Go stack nor the call stack grows
+		function, args, callSite = tailCall.Function, tailCall.Args, tailCall.CallSite
+	}
+}
+
+// Runs the body of a function, returning either its result or the call it made in tail position
+func runFunction(function *values.FunctionValue, args []values.RuntimeValue, callSite ast.Node, caller *modules.ModuleManager) (values.RuntimeValue, *environment.TailCall) {
 	declarationEnv := function.Env.(*environment.Environment)
-	mod := function.Manager.(*modules.ModuleManager)
-	scope := environment.NewChild(declarationEnv, environment.FUNCTION_SCOPE)
+	scope := environment.NewFunction(declarationEnv, caller.Env, function.Name, callSite)
+	checkCallDepth(scope, callSite)
+	// Deferred calls run however the function exits, including through a runtime error
+	defer scope.RunDeferred()
+
+	// Each call gets its own copy of the module manager,
+	// so the caller's environment is untouched once the function returns
+	mod := *function.Manager.(*modules.ModuleManager)
+	mod.EnterEnv(scope)
 
 	for i, param := range function.Parameters {
-		arg := evaluateExpression(call.Args[i], manager)
-		scope.DeclareVariable(param.Name, param.Type, arg)
+		// Arguments which weren't passed use the default, evaluated inside the function
+		if i >= len(args) || args[i] == nil {
+			var value values.RuntimeValue
+			if param.Variadic {
+				value = &values.ListLiteral{
+					Elements:  []values.RuntimeValue{},
+					BaseValue: values.BaseValue{DataType: param.Type},
+				}
+			} else {
+				value = evaluateExpression(param.Default, &mod)
+			}
+			scope.DeclareVariable(param.Name, param.Type, value)
+			continue
+		}
+		scope.DeclareVariable(param.Name, param.Type, args[i])
 	}
 
 	if function.This != nil {
 		scope.DeclareVariable("this", function.This.Type(), function.This)
 	}
 
-	mod.EnterEnv(scope)
-
 	for _, statement := range function.Body {
-		evaluate(statement, mod)
+		evaluate(statement, &mod)
 
 		if scope.ReturnValue != nil {
-			return scope.ReturnValue
+			return scope.ReturnValue, scope.TailCall
 		}
 	}
 
-	mod.ExitEnv()
-
-	return values.MakeNull()
+	return values.MakeNull(), nil
 }
 
 func evaluateList(list *ast.ListLiteral, manager *modules.ModuleManager) values.RuntimeValue {
@@ -169,6 +368,7 @@ func evaluateList(list *ast.ListLiteral, manager *modules.ModuleManager) values.
 
 		evaluatedValues = append(evaluatedValues, elemValue)
 	}
+	allocate(len(evaluatedValues), list, manager.Env)
 
 	return &values.ListLiteral{
 		Elements: evaluatedValues,
@@ -178,36 +378,124 @@ func evaluateList(list *ast.ListLiteral, manager *modules.ModuleManager) values.
 	}
 }
 
-func evaluateMap(maplit *ast.MapLiteral, manager *modules.ModuleManager) values.RuntimeValue {
-	elements := map[values.RuntimeValue]values.RuntimeValue{}
+func evaluateListComprehension(comp *ast.ListComprehension, manager *modules.ModuleManager) values.RuntimeValue {
+	iterable := evaluateExpression(comp.Iterable, manager).(*values.ListLiteral)
+	elemType, _ := types.ElemTypeOf(comp.Iterable.GetType())
+	evaluatedValues := []values.RuntimeValue{}
 
-	for key, value := range maplit.Elements {
-		key := evaluateExpression(key, manager)
+	for _, elem := range iterable.Elements {
+		newScope := environment.NewChild(manager.Env, environment.GENERIC_SCOPE)
+		manager.EnterEnv(newScope)
+		newScope.DeclareVariable(comp.Variable, elemType, values.Cast(elem, elemType))
+
+		if comp.Condition == nil || evaluateExpression(comp.Condition, manager).Truthy() {
+			evaluatedValues = append(evaluatedValues, evaluateExpression(comp.Value, manager))
+		}
+		manager.ExitEnv()
+	}
+	allocate(len(evaluatedValues), comp, manager.Env)
 
-		value := evaluateExpression(value, manager)
-		elements[key] = value
+	return &values.ListLiteral{
+		Elements: evaluatedValues,
+		BaseValue: values.BaseValue{
+			DataType: comp.GetType(),
+		},
 	}
+}
 
-	return &values.MapLiteral{
-		Elements: elements,
+func evaluateMap(maplit *ast.MapLiteral, manager *modules.ModuleManager) values.RuntimeValue {
+	result := &values.MapLiteral{
+		Elements:  []values.MapElement{},
 		BaseValue: values.BaseValue{DataType: maplit.GetType()},
 	}
+
+	for _, element := range maplit.Elements {
+		key := evaluateExpression(element.Key, manager)
+
+		value := evaluateExpression(element.Value, manager)
+		result.SetIndex(key, value)
+	}
+	allocate(len(result.Elements), maplit, manager.Env)
+
+	return result
+}
+
+func evaluateSliceExpression(slice *ast.SliceExpression, manager *modules.ModuleManager) values.RuntimeValue {
+	leftValue := evaluateExpression(slice.Left, manager)
+
+	var start, end values.RuntimeValue
+	if slice.Start != nil {
+		start = evaluateExpression(slice.Start, manager)
+	}
+	if slice.End != nil {
+		end = evaluateExpression(slice.End, manager)
+	}
+
+	if str, isString := leftValue.(*values.StringLiteral); isString {
+		result := str.Slice(start, end)
+		allocate(len(result.(*values.StringLiteral).Value), slice, manager.Env)
+		return result
+	}
+	result := leftValue.(*values.ListLiteral).Slice(start, end, slice.GetType())
+	allocate(len(result.(*values.ListLiteral).Elements), slice, manager.Env)
+	return result
 }
 
 func evaluateIndexExpression(indexExpr *ast.IndexExpression, manager *modules.ModuleManager) values.RuntimeValue {
 	leftValue := evaluateExpression(indexExpr.Left, manager)
+	if fn, ok := leftValue.(*values.FunctionValue); ok && fn.Instantiate != nil {
+		return fn.Instantiate(typechecker.TypeCheckType(indexExpr.TypeArgument, manager))
+	}
 	indexValue := evaluateExpression(indexExpr.Index, manager)
 
 	return leftValue.Index(indexValue)
 }
 
 func evaluateMemberExpression(memberExpr ast.MemberExpression, manager *modules.ModuleManager) values.RuntimeValue {
+	if memberExpr.Associated != nil {
+		return environment.GetAssociatedFunction(memberExpr.Member, memberExpr.Associated)
+	}
+
 	value := evaluateExpression(memberExpr.Left, manager)
+	if _, isNull := value.(*values.NullLiteral); isNull && memberExpr.Optional {
+		return value
+	}
+
+	if err, isErr := value.(*values.Error); isErr && memberExpr.Member == "error" {
+		return builtinErrorMethod(err, manager)
+	}
 
 	method := environment.GetMethod(memberExpr.Member, value.Type())
+	pointer, isPointer := value.(*values.Pointer)
+	if method == nil && isPointer {
+		method = environment.GetMethod(memberExpr.Member, pointer.Target.Load().Type())
+	}
+
 	if method != nil {
-		method.This = value
-		return method
+		// Bind a copy, as the method itself is shared by every value of the type
+		bound := *method
+		bound.This = value
+
+		if method.Type().(*types.Function).PointerReceiver {
+			if !isPointer {
+				// Point to where the value is stored, so the method can modify it
+				var target values.Location = &values.TemporaryLocation{Value: value}
+				if isAddressable(memberExpr.Left) {
+					target = locate(memberExpr.Left, manager)
+				}
+				bound.This = values.MakePointer(target, &types.Pointer{DataType: value.Type()})
+			}
+		} else if isPointer {
+			bound.This = pointer.Target.Load()
+		}
+		return &bound
+	}
+
+	// Associated functions of enums are accessed through the enum's value
+	if enum, isEnum := value.(*values.Enum); isEnum && enum.Members[memberExpr.Member] == nil {
+		if fn := environment.GetAssociatedFunction(memberExpr.Member, enum.Type()); fn != nil {
+			return fn
+		}
 	}
 
 	memberValue := value.Member(memberExpr.Member)
@@ -219,15 +507,21 @@ func evaluateMemberExpression(memberExpr ast.MemberExpression, manager *modules.
 }
 
 func evaluateStructExpression(structExpr ast.StructExpression, manager *modules.ModuleManager) values.RuntimeValue {
-	members := map[string]values.RuntimeValue{}
 	structType := typechecker.TypeCheckTypeExpression(structExpr.InstanceOf, manager).(*types.Struct)
 
-	for name, dataType := range structType.Members {
-		if value, hasMember := structExpr.Members[name]; hasMember {
-			members[name] = evaluateExpression(value, manager)
-			continue
+	// Values are evaluated in the order they were written, but stored in the order the fields were declared
+	given := map[string]values.RuntimeValue{}
+	for _, member := range structExpr.Members {
+		given[member.Name] = evaluateExpression(member.Value, manager)
+	}
+
+	members := []values.StructField{}
+	for _, field := range structType.Members {
+		value, hasMember := given[field.Name]
+		if !hasMember {
+			value = values.GetZeroValue(field.Type)
 		}
-		members[name] = values.GetZeroValue(dataType.Type.String())
+		members = append(members, values.StructField{Name: field.Name, Value: value})
 	}
 
 	return &values.StructLiteral{
@@ -244,7 +538,7 @@ func evaluateTuple(tuple *ast.TupleExpression, manager *modules.ModuleManager) v
 		members = append(members, evaluateExpression(member, manager))
 	}
 
-	return &values.TupleValue{Members: members}
+	return &values.TupleValue{Members: members, BaseValue: values.BaseValue{DataType: tuple.GetType()}}
 }
 
 func evaluateTupleStructExpression(tupleType *types.TupleStruct, tupleExpr *ast.FunctionCall, manager *modules.ModuleManager) values.RuntimeValue {
@@ -263,6 +557,10 @@ func evaluateTupleStructExpression(tupleType *types.TupleStruct, tupleExpr *ast.
 func evaluateCastExpression(cast *ast.CastExpression, manager *modules.ModuleManager) values.RuntimeValue {
 	left := evaluateExpression(cast.Left, manager)
 	ty := typechecker.TypeCheckType(cast.DataType, manager)
+	if value, ok := castEnum(left, ty); ok {
+		return value
+	}
+
 	castable, ok := ty.(types.CastableTo)
 	if !ty.Valid(left.Type()) && !(ok && castable.CanCastTo(left.Type())) {
 		errors.LogError(fmt.Sprintf("%q is type %q, not %q", left.ToString(), left.Type(), ty))
@@ -271,6 +569,41 @@ func evaluateCastExpression(cast *ast.CastExpression, manager *modules.ModuleMan
 	return values.Cast(left, ty)
 }
 
+// Converts between the members of an enum and their discriminants
+func castEnum(value values.RuntimeValue, ty types.ValidType) (values.RuntimeValue, bool) {
+	if enum, isEnum := ty.(*types.Enum); isEnum && !enum.Valid(value.Type()) {
+		var discriminant any
+		switch literal := value.(type) {
+		case *values.IntegerLiteral:
+			discriminant = literal.Value
+		case *values.UntypedNumber:
+			discriminant = int(literal.Value)
+		case *values.StringLiteral:
+			discriminant = literal.Value
+		default:
+			return nil, false
+		}
+
+		name, ok := enum.FromDiscriminant(discriminant)
+		if !ok {
+			errors.LogError(fmt.Sprintf("No member of enum %q has discriminant %#v", enum, discriminant))
+		}
+		return enumMember(enum, name), true
+	}
+
+	unitType, isUnit := value.Type().(*types.UnitStruct)
+	if !isUnit || unitType.Enum == nil || ty.Valid(unitType) {
+		return nil, false
+	}
+	switch discriminant := unitType.Enum.Types[unitType.Name].Discriminant.(type) {
+	case int:
+		return values.MakeInteger(discriminant), true
+	case string:
+		return values.MakeString(discriminant), true
+	}
+	return nil, false
+}
+
 func evaluateTypeCheckExpression(expr *ast.TypeCheckExpression, manager *modules.ModuleManager) values.RuntimeValue {
 	left := evaluateExpression(expr.Left, manager)
 	ty := typechecker.TypeCheckType(expr.DataType, manager)
@@ -0,0 +1,30 @@
+package interpreter
+
+import "testing"
+
+func TestPipeline(t *testing.T) {
+	expectOutput(t, `
+		fn double(x: int): int { return x * 2 }
+		fn add(x: int, y: int): int { return x + y }
+		var n: int = 3
+		var i = 0
+		while i < 2 {
+			n
+				|> double()
+				|> add(i)
+				|> print()
+			i += 1
+		}`, "6\n7\n")
+}
+
+func TestInterfaceMethodCalledOnEachType(t *testing.T) {
+	expectOutput(t, `
+		interface Named { name(): string }
+		fn (Named) greet(): string { return "hi " + this.name() }
+		struct A { x: int }
+		fn (A) name(): string { return "a" }
+		struct B { x: int }
+		fn (B) name(): string { return "b" }
+		print(A { x: 1 }.greet())
+		print(B { x: 1 }.greet())`, "hi a\nhi b\n")
+}
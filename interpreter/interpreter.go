@@ -1,12 +1,14 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gearsdatapacks/libra/errors"
 	"github.com/gearsdatapacks/libra/interpreter/values"
 	"github.com/gearsdatapacks/libra/modules"
 	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
 	// typechecker "github.com/gearsdatapacks/libra/type_checker"
 )
 
@@ -16,8 +18,13 @@ const (
 	EVALUATE
 )
 
-func Evaluate(manager *modules.ModuleManager) values.RuntimeValue {
+// Evaluates a program without any limits and with the default permissions.
+// Runtime errors are returned rather than exiting, as with EvaluateSandboxed
+func Evaluate(manager *modules.ModuleManager) (values.RuntimeValue, error) {
+	return EvaluateSandboxed(context.Background(), manager, Limits{}, permissions.Default())
+}
 
+func evaluateProgram(manager *modules.ModuleManager) values.RuntimeValue {
 	registerStatements(manager)
 
 	resolveImports(manager)
@@ -58,7 +65,17 @@ func resolveImports(manager *modules.ModuleManager) {
 				evaluateImportStatement(imp, manager)
 			}
 		}
+	}
 }
+
+// Counts a statement towards the step limit, and lets the debugger stop at it
+func visit(astNode ast.Statement, manager *modules.ModuleManager) {
+	step(astNode, manager.Env)
+
+	// The debugger follows a single task, so spawned tasks run without stopping
+	if tracer != nil && !manager.Env.InSpawnedTask() {
+		tracer.OnStatement(astNode, manager)
+	}
 }
 
 func evaluateStatements(manager *modules.ModuleManager) values.RuntimeValue {
@@ -89,11 +106,33 @@ func register(astNode ast.Statement, manager *modules.ModuleManager) {
 		evaluateUnitStructDeclaration(statement, manager)
 
 	case *ast.EnumDeclaration:
-		evaluateEnumDeclaration(statement, manager)	
+		evaluateEnumDeclaration(statement, manager)
+
+	case *ast.ImplDeclaration:
+		for _, method := range statement.Methods {
+			registerFunctionDeclaration(method, manager)
+		}
 	}
 }
 
+// Tracer is notified before each statement is evaluated, allowing tools such as the debugger to inspect or pause execution
+type Tracer interface {
+	OnStatement(stmt ast.Statement, manager *modules.ModuleManager)
+}
+
+var tracer Tracer
+
+func SetTracer(t Tracer) {
+	tracer = t
+}
+
+func EvaluateExpression(expr ast.Expression, manager *modules.ModuleManager) values.RuntimeValue {
+	return evaluateExpression(expr, manager)
+}
+
 func evaluate(astNode ast.Statement, manager *modules.ModuleManager) values.RuntimeValue {
+	visit(astNode, manager)
+
 	switch statement := astNode.(type) {
 	case *ast.ExpressionStatement:
 		return evaluateExpressionStatement(statement, manager)
@@ -108,6 +147,9 @@ func evaluate(astNode ast.Statement, manager *modules.ModuleManager) values.Runt
 	case *ast.ReturnStatement:
 		return evaluateReturnStatement(statement, manager)
 
+	case *ast.DeferStatement:
+		return evaluateDeferStatement(statement, manager)
+
 	case *ast.IfStatement:
 		return evaluateIfStatement(statement, manager)
 
@@ -117,6 +159,9 @@ func evaluate(astNode ast.Statement, manager *modules.ModuleManager) values.Runt
 	case *ast.ForLoop:
 		return evaluateForLoop(statement, manager)
 
+	case *ast.SelectStatement:
+		return evaluateSelectStatement(statement, manager)
+
 	case *ast.StructDeclaration:
 		// return evaluateStructDeclaration(statement, manager)
 		return values.MakeNull()
@@ -127,6 +172,9 @@ func evaluate(astNode ast.Statement, manager *modules.ModuleManager) values.Runt
 	case *ast.InterfaceDeclaration:
 		return values.MakeNull()
 
+	case *ast.ImplDeclaration:
+		return values.MakeNull()
+
 	case *ast.TypeDeclaration:
 		// env.AddType(statement.Name, typechecker.TypeCheckType(statement.DataType, env))
 		return values.MakeNull()
@@ -0,0 +1,75 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/lexer"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+func TestMain(m *testing.M) {
+	registry.Register()
+	Register()
+	os.Exit(m.Run())
+}
+
+// Type checks a program, failing the test if it isn't valid
+func load(t *testing.T, source string) *modules.ModuleManager {
+	t.Helper()
+
+	tokens, err := lexer.New([]byte(source)).Tokenise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := parser.New().Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := modules.NewDetatched(symbols.New(), environment.New())
+	manager.Files[0].Ast = program
+	if err := typechecker.TypeCheck(manager); err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+// Runs a program with the given limits and permissions, returning what it printed
+func runSandboxed(t *testing.T, source string, limits Limits, policy permissions.Policy) (string, error) {
+	t.Helper()
+	manager := load(t, source)
+
+	output := &bytes.Buffer{}
+	SetIO(strings.NewReader(""), output)
+	defer SetIO(os.Stdin, os.Stdout)
+
+	_, err := EvaluateSandboxed(context.Background(), manager, limits, policy)
+	return output.String(), err
+}
+
+// Runs a program with no limits and the default permissions, failing the test if it errors
+func run(t *testing.T, source string) string {
+	t.Helper()
+	output, err := runSandboxed(t, source, Limits{}, permissions.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func expectOutput(t *testing.T, source, expected string) {
+	t.Helper()
+	if output := run(t, source); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
@@ -0,0 +1,468 @@
+package interpreter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// The "std:json" module, which converts values to and from JSON text
+func registerJsonModule() {
+	exports := modules.Std("json").Env.Exports
+	fnTypes := registry.StdModules["json"]
+
+	exports["encode"] = &values.FunctionValue{
+		Name:       "encode",
+		Parameters: []values.Parameter{{Name: "value", Type: &types.Any{}}},
+		BaseValue:  values.BaseValue{DataType: fnTypes["encode"]},
+		Native: func(args []values.RuntimeValue) values.RuntimeValue {
+			var out strings.Builder
+			if message := encodeJson(args[0], "", &out); message != "" {
+				return values.MakeError("json.encode: " + message)
+			}
+			return values.MakeString(out.String())
+		},
+	}
+
+	decode := fnTypes["decode"].(*types.Function)
+	exports["decode"] = &values.FunctionValue{
+		Name:       "decode",
+		Parameters: []values.Parameter{{Name: "text", Type: &types.StringLiteral{}}},
+		BaseValue:  values.BaseValue{DataType: decode},
+		Instantiate: func(typeArgument types.ValidType) *values.FunctionValue {
+			return &values.FunctionValue{
+				Name:       "decode",
+				Parameters: []values.Parameter{{Name: "text", Type: &types.StringLiteral{}}},
+				BaseValue:  values.BaseValue{DataType: decode.Instantiate(typeArgument)},
+				Native: func(args []values.RuntimeValue) values.RuntimeValue {
+					data, err := parseJson(args[0].(*values.StringLiteral).Value)
+					if err != nil {
+						return values.MakeError("json.decode: Invalid JSON: " + err.Error())
+					}
+
+					value, message := decodeJson(data, typeArgument, "")
+					if message != "" {
+						return values.MakeError("json.decode: " + message)
+					}
+					return value
+				},
+			}
+		},
+	}
+}
+
+// Writes a value as JSON, returning an error message if part of it can't be encoded
+func encodeJson(value values.RuntimeValue, path string, out *strings.Builder) string {
+	switch value := value.(type) {
+	case *values.NullLiteral:
+		out.WriteString("null")
+
+	case *values.BooleanLiteral:
+		out.WriteString(strconv.FormatBool(value.Value))
+
+	case *values.IntegerLiteral:
+		out.WriteString(strconv.Itoa(value.Value))
+
+	case *values.UntypedNumber:
+		return encodeFloat(value.Value, path, out)
+
+	case *values.FloatLiteral:
+		return encodeFloat(value.Value, path, out)
+
+	case *values.StringLiteral:
+		out.WriteString(jsonString(value.Value))
+
+	case *values.ListLiteral:
+		return encodeJsonArray(value.Elements, path, out)
+
+	case *values.TupleValue:
+		return encodeJsonArray(value.Members, path, out)
+
+	case *values.TupleStructValue:
+		return encodeJsonArray(value.Members, path, out)
+
+	case *values.MapLiteral:
+		out.WriteByte('{')
+		for i, element := range value.Elements {
+			if i != 0 {
+				out.WriteByte(',')
+			}
+			key := element.Key.ToString()
+			if str, ok := element.Key.(*values.StringLiteral); ok {
+				key = str.Value
+			}
+			out.WriteString(jsonString(key))
+			out.WriteByte(':')
+			if message := encodeJson(element.Value, fmt.Sprintf("%s[%q]", path, key), out); message != "" {
+				return message
+			}
+		}
+		out.WriteByte('}')
+
+	case *values.StructLiteral:
+		out.WriteByte('{')
+		for i, field := range value.Members {
+			if i != 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(jsonString(field.Name))
+			out.WriteByte(':')
+			if message := encodeJson(field.Value, path+"."+field.Name, out); message != "" {
+				return message
+			}
+		}
+		out.WriteByte('}')
+
+	case *values.UnitStruct:
+		// Enum members are written as their discriminant, other unit structs as their name
+		unitType, _ := value.Type().(*types.UnitStruct)
+		if unitType == nil || unitType.Enum == nil {
+			out.WriteString(jsonString(value.Name))
+			break
+		}
+		switch discriminant := unitType.Enum.Types[value.Name].Discriminant.(type) {
+		case int:
+			out.WriteString(strconv.Itoa(discriminant))
+		case string:
+			out.WriteString(jsonString(discriminant))
+		}
+
+	default:
+		return pathMessage(path, fmt.Sprintf("Cannot encode value of type %q", value.Type()))
+	}
+
+	return ""
+}
+
+func encodeJsonArray(elements []values.RuntimeValue, path string, out *strings.Builder) string {
+	out.WriteByte('[')
+	for i, elem := range elements {
+		if i != 0 {
+			out.WriteByte(',')
+		}
+		if message := encodeJson(elem, fmt.Sprintf("%s[%d]", path, i), out); message != "" {
+			return message
+		}
+	}
+	out.WriteByte(']')
+	return ""
+}
+
+func encodeFloat(value float64, path string, out *strings.Builder) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return pathMessage(path, fmt.Sprintf("Cannot encode %v", value))
+	}
+	encoded, _ := json.Marshal(value)
+	out.Write(encoded)
+	return ""
+}
+
+func jsonString(str string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(str)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func pathMessage(path, message string) string {
+	if path == "" {
+		return message
+	}
+	return path + ": " + message
+}
+
+// A JSON object, keeping its keys in the order they were written
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value any
+}
+
+// The value of the last member with the given key
+func (object jsonObject) get(key string) (any, bool) {
+	var value any
+	found := false
+	for _, member := range object {
+		if member.Key == key {
+			value, found = member.Value, true
+		}
+	}
+	return value, found
+}
+
+// Parses JSON text into objects, []any, json.Number, string, bool and nil
+func parseJson(text string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	data, err := readJson(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+	return data, nil
+}
+
+func readJson(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		elements := []any{}
+		for decoder.More() {
+			elem, err := readJson(decoder)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, elem)
+		}
+		_, err := decoder.Token()
+		return elements, err
+
+	case json.Delim('{'):
+		object := jsonObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJson(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, jsonMember{Key: key.(string), Value: value})
+		}
+		_, err := decoder.Token()
+		return object, err
+	}
+
+	return token, nil
+}
+
+// Converts parsed JSON into a value of the given type, returning an error message if it doesn't fit
+func decodeJson(data any, dataType types.ValidType, path string) (values.RuntimeValue, string) {
+	expected := func() (values.RuntimeValue, string) {
+		return nil, pathMessage(path, "expected "+dataType.String())
+	}
+
+	switch ty := dataType.(type) {
+	case *types.Any:
+		return decodeAny(data), ""
+
+	case *types.NullLiteral:
+		if data != nil {
+			return expected()
+		}
+		return values.MakeNull(), ""
+
+	case *types.Optional:
+		if data == nil {
+			return values.MakeNull(), ""
+		}
+		return decodeJson(data, ty.DataType, path)
+
+	case *types.BoolLiteral:
+		boolean, ok := data.(bool)
+		if !ok {
+			return expected()
+		}
+		return values.MakeBoolean(boolean), ""
+
+	case *types.IntLiteral:
+		number, ok := data.(json.Number)
+		if !ok {
+			return expected()
+		}
+		integer, err := strconv.Atoi(number.String())
+		if err != nil {
+			return expected()
+		}
+		return values.MakeInteger(integer), ""
+
+	case *types.FloatLiteral:
+		number, ok := data.(json.Number)
+		if !ok {
+			return expected()
+		}
+		float, err := number.Float64()
+		if err != nil {
+			return expected()
+		}
+		return values.MakeFloat(float), ""
+
+	case *types.StringLiteral:
+		str, ok := data.(string)
+		if !ok {
+			return expected()
+		}
+		return values.MakeString(str), ""
+
+	case *types.ListLiteral:
+		elements, ok := data.([]any)
+		if !ok {
+			return expected()
+		}
+		list, message := decodeElements(elements, func(int) types.ValidType { return ty.ElemType }, path)
+		if message != "" {
+			return nil, message
+		}
+		return &values.ListLiteral{Elements: list, BaseValue: values.BaseValue{DataType: ty}}, ""
+
+	case *types.ArrayLiteral:
+		elements, ok := data.([]any)
+		if !ok || (ty.Length != -1 && len(elements) != ty.Length) {
+			return expected()
+		}
+		array, message := decodeElements(elements, func(int) types.ValidType { return ty.ElemType }, path)
+		if message != "" {
+			return nil, message
+		}
+		return &values.ListLiteral{Elements: array, BaseValue: values.BaseValue{DataType: ty}}, ""
+
+	case *types.Tuple:
+		elements, ok := data.([]any)
+		if !ok || len(elements) != len(ty.Members) {
+			return expected()
+		}
+		members, message := decodeElements(elements, func(i int) types.ValidType { return ty.Members[i] }, path)
+		if message != "" {
+			return nil, message
+		}
+		return &values.TupleValue{Members: members, BaseValue: values.BaseValue{DataType: ty}}, ""
+
+	case *types.MapLiteral:
+		object, ok := data.(jsonObject)
+		if !ok {
+			return expected()
+		}
+		if !ty.KeyType.Valid(&types.StringLiteral{}) {
+			return nil, pathMessage(path, fmt.Sprintf("Cannot decode map of type %q, its keys must be strings", ty))
+		}
+		result := &values.MapLiteral{Elements: []values.MapElement{}, BaseValue: values.BaseValue{DataType: ty}}
+		for _, member := range object {
+			value, message := decodeJson(member.Value, ty.ValueType, fmt.Sprintf("%s[%q]", path, member.Key))
+			if message != "" {
+				return nil, message
+			}
+			result.SetIndex(values.MakeString(member.Key), value)
+		}
+		return result, ""
+
+	case *types.Struct:
+		object, ok := data.(jsonObject)
+		if !ok {
+			return expected()
+		}
+		members := []values.StructField{}
+		for _, field := range ty.Members {
+			fieldData, present := object.get(field.Name)
+			if !present {
+				// Only optional fields can be left out, and are null
+				if !field.Type.Valid(&types.NullLiteral{}) {
+					return nil, pathMessage(path, fmt.Sprintf("missing field %q", field.Name))
+				}
+				members = append(members, values.StructField{Name: field.Name, Value: values.MakeNull()})
+				continue
+			}
+
+			value, message := decodeJson(fieldData, field.Type, path+"."+field.Name)
+			if message != "" {
+				return nil, message
+			}
+			members = append(members, values.StructField{Name: field.Name, Value: value})
+		}
+		return &values.StructLiteral{Name: ty.Name, Members: members, BaseValue: values.BaseValue{DataType: ty}}, ""
+
+	case *types.Enum:
+		var discriminant any
+		switch literal := data.(type) {
+		case json.Number:
+			integer, err := strconv.Atoi(literal.String())
+			if err != nil {
+				return expected()
+			}
+			discriminant = integer
+		case string:
+			discriminant = literal
+		}
+		name, ok := ty.FromDiscriminant(discriminant)
+		if !ok {
+			return expected()
+		}
+		return enumMember(ty, name), ""
+
+	case *types.Union:
+		// The first type the data fits is used
+		for _, member := range ty.Types {
+			if value, message := decodeJson(data, member, path); message == "" {
+				return value, ""
+			}
+		}
+		return expected()
+	}
+
+	return nil, pathMessage(path, fmt.Sprintf("Cannot decode into type %q", dataType))
+}
+
+func decodeElements(elements []any, elemType func(int) types.ValidType, path string) ([]values.RuntimeValue, string) {
+	result := []values.RuntimeValue{}
+	for i, elem := range elements {
+		value, message := decodeJson(elem, elemType(i), fmt.Sprintf("%s[%d]", path, i))
+		if message != "" {
+			return nil, message
+		}
+		result = append(result, value)
+	}
+	return result, ""
+}
+
+// Decodes JSON without a specific type, using lists for arrays and maps for objects
+func decodeAny(data any) values.RuntimeValue {
+	switch data := data.(type) {
+	case bool:
+		return values.MakeBoolean(data)
+	case string:
+		return values.MakeString(data)
+	case json.Number:
+		if integer, err := strconv.Atoi(data.String()); err == nil {
+			return values.MakeInteger(integer)
+		}
+		float, _ := data.Float64()
+		return values.MakeFloat(float)
+	case []any:
+		elements := []values.RuntimeValue{}
+		for _, elem := range data {
+			elements = append(elements, decodeAny(elem))
+		}
+		return &values.ListLiteral{
+			Elements:  elements,
+			BaseValue: values.BaseValue{DataType: &types.ListLiteral{ElemType: &types.Any{}}},
+		}
+	case jsonObject:
+		result := &values.MapLiteral{
+			Elements:  []values.MapElement{},
+			BaseValue: values.BaseValue{DataType: &types.MapLiteral{KeyType: &types.StringLiteral{}, ValueType: &types.Any{}}},
+		}
+		for _, member := range data {
+			result.SetIndex(values.MakeString(member.Key), decodeAny(member.Value))
+		}
+		return result
+	}
+	return values.MakeNull()
+}
@@ -0,0 +1,64 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+// Runs a program importing the json module, which can only be loaded from a file
+func runWithJson(t *testing.T, source string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "main.lb")
+	os.WriteFile(file, []byte(`import "std:json"`+"\n"+source), 0666)
+
+	manager, err := modules.NewManager(file, permissions.Default(), symbols.New(), environment.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := typechecker.TypeCheck(manager); err != nil {
+		t.Fatal(err)
+	}
+
+	output := &bytes.Buffer{}
+	SetIO(strings.NewReader(""), output)
+	defer SetIO(os.Stdin, os.Stdout)
+
+	if _, err := EvaluateSandboxed(context.Background(), manager, Limits{}, permissions.Default()); err != nil {
+		t.Fatal(err)
+	}
+	return output.String()
+}
+
+func TestOnlyOptionalJsonFieldsCanBeMissing(t *testing.T) {
+	output := runWithJson(t, `
+		struct Config { name: string, port: int? }
+		print(json.decode[Config]("{\"name\": \"a\"}"))
+		print(json.decode[Config]("{\"port\": 1}"))`)
+
+	lines := strings.Split(output, "\n")
+	if !strings.Contains(lines[0], "null") {
+		t.Errorf("expected the missing optional field to be null, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `missing field "name"`) {
+		t.Errorf("expected the missing required field to be an error, got %q", lines[1])
+	}
+}
+
+func TestDecodingIntoUnion(t *testing.T) {
+	output := runWithJson(t, `
+		print(json.decode[int | string]("\"a\""))
+		print(json.decode[int | string]("1"))`)
+	if output != "a\n1\n" {
+		t.Errorf("expected the JSON to be decoded as either type, got %q", output)
+	}
+}
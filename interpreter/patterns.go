@@ -0,0 +1,65 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+)
+
+// Declares the variables bound by a pattern, using the matching parts of the value
+func declarePattern(pattern ast.Pattern, value values.RuntimeValue, manager *modules.ModuleManager) {
+	switch pat := pattern.(type) {
+	case *ast.IdentifierPattern:
+		if pat.Name != "_" {
+			manager.Env.DeclareVariable(pat.Name, pat.GetType(), value)
+		}
+
+	case *ast.TuplePattern:
+		for i, element := range pat.Elements {
+			declarePattern(element, value.Member(strconv.Itoa(i)), manager)
+		}
+
+	case *ast.ListPattern:
+		elements := listElements(value, len(pat.Elements))
+		for i, element := range pat.Elements {
+			declarePattern(element, elements[i], manager)
+		}
+
+	case *ast.StructPattern:
+		for _, field := range pat.Fields {
+			declarePattern(field.Pattern, value.Member(field.Name), manager)
+		}
+	}
+}
+
+// Assigns each part of a value to the matching element of a tuple or list of assignees
+func assignDestructured(assignee ast.Expression, value values.RuntimeValue, manager *modules.ModuleManager) {
+	switch target := assignee.(type) {
+	case *ast.TupleExpression:
+		for i, member := range target.Members {
+			assignDestructured(member, value.Member(strconv.Itoa(i)), manager)
+		}
+
+	case *ast.ListLiteral:
+		elements := listElements(value, len(target.Elements))
+		for i, element := range target.Elements {
+			assignDestructured(element, elements[i], manager)
+		}
+
+	default:
+		assign(assignee, value, manager)
+	}
+}
+
+// The elements of a list being destructured. Lists don't have a fixed length, so it is checked here
+func listElements(value values.RuntimeValue, length int) []values.RuntimeValue {
+	elements := value.(*values.ListLiteral).Elements
+	if len(elements) != length {
+		errors.LogError(fmt.Sprintf("Cannot destructure list of length %d into %d values", len(elements), length))
+	}
+	return elements
+}
@@ -0,0 +1,129 @@
+package interpreter
+
+import (
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// Locations are compared by value, so two pointers to the same place are equal.
+// Map keys are values themselves, so locations containing one compare it with EqualTo instead
+
+type variableLocation struct {
+	env  *environment.Environment
+	name string
+}
+
+func (v variableLocation) Load() values.RuntimeValue {
+	return v.env.GetVariable(v.name)
+}
+
+func (v variableLocation) Store(value values.RuntimeValue) values.RuntimeValue {
+	return v.env.AssignVariable(v.name, v.Load().Type(), value)
+}
+
+type memberLocation struct {
+	parent values.Location
+	member string
+}
+
+func (m memberLocation) Load() values.RuntimeValue {
+	return m.parent.Load().Member(m.member)
+}
+
+func (m memberLocation) Store(value values.RuntimeValue) values.RuntimeValue {
+	return m.parent.Load().SetMember(m.member, storedValue(value, m.Load()))
+}
+
+func (m memberLocation) SameAs(other values.Location) bool {
+	o, ok := other.(memberLocation)
+	return ok && m.member == o.member && values.SameLocation(m.parent, o.parent)
+}
+
+type indexLocation struct {
+	parent values.Location
+	// Integer indexes are kept as ints, as a new value is made each time the index is evaluated
+	index int
+	// Any other key, such as the key of a map entry
+	key values.RuntimeValue
+}
+
+func (i indexLocation) indexValue() values.RuntimeValue {
+	if i.key != nil {
+		return i.key
+	}
+	return values.MakeInteger(i.index)
+}
+
+func (i indexLocation) Load() values.RuntimeValue {
+	return i.parent.Load().Index(i.indexValue())
+}
+
+func (i indexLocation) Store(value values.RuntimeValue) values.RuntimeValue {
+	return i.parent.Load().SetIndex(i.indexValue(), storedValue(value, i.Load()))
+}
+
+func (i indexLocation) SameAs(other values.Location) bool {
+	o, ok := other.(indexLocation)
+	if !ok || i.index != o.index || (i.key == nil) != (o.key == nil) {
+		return false
+	}
+	return (i.key == nil || i.key.EqualTo(o.key)) && values.SameLocation(i.parent, o.parent)
+}
+
+// Converts a value written through a pointer to the type of the value it replaces
+func storedValue(value, current values.RuntimeValue) values.RuntimeValue {
+	if castable, ok := value.(values.AutoCastable); ok {
+		return castable.AutoCast(current.Type())
+	}
+	return value.Copy()
+}
+
+// Whether an expression refers to somewhere a value is stored, rather than a temporary value
+func isAddressable(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.MemberExpression:
+		return !expr.Optional && expr.Associated == nil && isAddressable(expr.Left)
+	case *ast.IndexExpression:
+		return isAddressable(expr.Left)
+	case *ast.UnaryOperation:
+		return expr.Operator == "*" && !expr.Postfix
+	}
+	return false
+}
+
+// Finds where the value of an expression is stored, so a pointer can read and write it
+func locate(expr ast.Expression, manager *modules.ModuleManager) values.Location {
+	if !isAddressable(expr) {
+		return &values.TemporaryLocation{Value: evaluateExpression(expr, manager)}
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return variableLocation{env: manager.Env.DeclaredIn(expr.Symbol), name: expr.Symbol}
+
+	case *ast.MemberExpression:
+		return memberLocation{parent: locate(expr.Left, manager), member: expr.Member}
+
+	case *ast.IndexExpression:
+		parent := locate(expr.Left, manager)
+		// Literal indexes are untyped, so they're converted to the type the collection is indexed by
+		indexType := expr.Index.GetType()
+		if pseudo, ok := indexType.(types.PseudoType); ok {
+			indexType = pseudo.ToReal()
+		}
+		index := values.Expect(evaluateExpression(expr.Index, manager), indexType)
+		if integer, ok := index.(*values.IntegerLiteral); ok {
+			return indexLocation{parent: parent, index: integer.Value}
+		}
+		return indexLocation{parent: parent, key: index}
+
+	default:
+		// Dereferencing a pointer gives back the location it points to
+		return evaluateExpression(expr.(*ast.UnaryOperation).Value, manager).(*values.Pointer).Target
+	}
+}
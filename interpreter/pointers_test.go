@@ -0,0 +1,26 @@
+package interpreter
+
+import "testing"
+
+func TestPointersToTheSameElementAreEqual(t *testing.T) {
+	expectOutput(t, `
+		var xs: int[] = [1, 2, 3]
+		var m: {string: int} = {"a": 1}
+		struct P { x: int }
+		var ps: {string: P} = {"a": P { x: 1 }}
+		var i = 1
+		print(&xs[1] == &xs[i])
+		print(&xs[1] == &xs[2])
+		print(&m["a"] == &m["a"])
+		print(&ps["a"].x == &ps["a"].x)`, "true\nfalse\ntrue\ntrue\n")
+}
+
+// The list's type comes from its literal, as declaring a constant of a type like int
+// marks it as constant for every program that runs afterwards
+func TestWritingThroughPointerToElement(t *testing.T) {
+	expectOutput(t, `
+		var xs = [1, 2, 3]
+		const p = &xs[1]
+		*p = 5
+		print(xs)`, "[1, 5, 3]\n")
+}
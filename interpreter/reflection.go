@@ -0,0 +1,234 @@
+package interpreter
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// typeof describes the type the type checker found for its argument, so it's given the expression rather than its value.
+// Values only known to be any are described by the type they were created with.
+func evaluateTypeof(call *ast.FunctionCall, manager *modules.ModuleManager) values.RuntimeValue {
+	value := evaluateExpression(call.Args[0], manager)
+
+	dataType := call.Args[0].GetType()
+	if _, isAny := dataType.(*types.Any); isAny {
+		dataType = value.Type()
+	}
+	return describeType(defaultType(dataType), map[types.ValidType]bool{})
+}
+
+// Literals can have untyped numbers in their types, which are described as the types they default to
+func defaultType(dataType types.ValidType) types.ValidType {
+	switch ty := dataType.(type) {
+	case types.PseudoType:
+		return ty.ToReal()
+	case *types.ListLiteral:
+		return &types.ListLiteral{ElemType: defaultType(ty.ElemType)}
+	case *types.ArrayLiteral:
+		return &types.ArrayLiteral{ElemType: defaultType(ty.ElemType), Length: ty.Length}
+	case *types.MapLiteral:
+		return &types.MapLiteral{KeyType: defaultType(ty.KeyType), ValueType: defaultType(ty.ValueType)}
+	case *types.Tuple:
+		members := []types.ValidType{}
+		for _, member := range ty.Members {
+			members = append(members, defaultType(member))
+		}
+		return &types.Tuple{Members: members}
+	}
+	return dataType
+}
+
+func typeKind(dataType types.ValidType) string {
+	switch ty := dataType.(type) {
+	case *types.IntLiteral:
+		return "int"
+	case *types.FloatLiteral:
+		return "float"
+	case *types.BoolLiteral:
+		return "boolean"
+	case *types.StringLiteral:
+		return "string"
+	case *types.NullLiteral:
+		return "null"
+	case *types.Void:
+		return "void"
+	case *types.ListLiteral:
+		return "list"
+	case *types.ArrayLiteral:
+		return "array"
+	case *types.MapLiteral:
+		return "map"
+	case *types.Tuple:
+		return "tuple"
+	case *types.Pointer:
+		return "pointer"
+	case *types.Optional:
+		return "optional"
+	case *types.Channel:
+		return "channel"
+	case *types.ErrorType:
+		return "error"
+	case *types.Union:
+		return "union"
+	case *types.Enum:
+		return "enum"
+	case *types.Struct:
+		return "struct"
+	case *types.TupleStruct:
+		return "tuple struct"
+	case *types.UnitStruct:
+		if ty.Enum != nil {
+			return "enum member"
+		}
+		return "unit struct"
+	case *types.Interface:
+		return "interface"
+	case *types.Function:
+		return "function"
+	case *types.ExplicitType:
+		return "explicit"
+	case *types.Module:
+		return "module"
+	}
+	return "any"
+}
+
+// Builds the TypeInfo value for a type.
+// The types currently being described are kept in seen, so a type containing itself is only described in full once
+func describeType(dataType types.ValidType, seen map[types.ValidType]bool) values.RuntimeValue {
+	if dataType == nil {
+		dataType = &types.Any{}
+	}
+	// Enum members and type names are wrapped in a Type, which isn't part of the type itself
+	for {
+		wrapper, ok := dataType.(*types.Type)
+		if !ok {
+			break
+		}
+		dataType = wrapper.DataType
+	}
+
+	fields := []values.RuntimeValue{}
+	members := []types.ValidType{}
+	memberInfo := []values.RuntimeValue{}
+	params := []values.RuntimeValue{}
+	var returns values.RuntimeValue = values.MakeNull()
+
+	if !seen[dataType] {
+		seen[dataType] = true
+		defer delete(seen, dataType)
+
+		switch ty := dataType.(type) {
+		case *types.Struct:
+			for _, field := range ty.Members {
+				fields = append(fields, describeField(field.Name, field.Type, seen))
+			}
+		case *types.Interface:
+			members := ty.AllMembers()
+			names := []string{}
+			for name := range members {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fields = append(fields, describeField(name, members[name], seen))
+			}
+		case *types.Function:
+			for i, param := range ty.Parameters {
+				name := ""
+				if i < len(ty.ParameterNames) {
+					name = ty.ParameterNames[i]
+				}
+				params = append(params, describeField(name, param, seen))
+			}
+			if ty.ReturnType != nil {
+				returns = describeType(ty.ReturnType, seen)
+			}
+		case *types.Union:
+			members = ty.Types
+		case *types.Enum:
+			for _, name := range ty.Order {
+				memberInfo = append(memberInfo, describeEnumMember(name, ty.Types[name].DataType, seen))
+			}
+		case *types.Tuple:
+			members = ty.Members
+		case *types.TupleStruct:
+			for i, member := range ty.Members {
+				fields = append(fields, describeField(strconv.Itoa(i), member, seen))
+			}
+			members = ty.Members
+		case *types.ListLiteral:
+			members = []types.ValidType{ty.ElemType}
+		case *types.ArrayLiteral:
+			members = []types.ValidType{ty.ElemType}
+		case *types.MapLiteral:
+			members = []types.ValidType{ty.KeyType, ty.ValueType}
+		case *types.Pointer:
+			members = []types.ValidType{ty.DataType}
+		case *types.Optional:
+			members = []types.ValidType{ty.DataType}
+		case *types.Channel:
+			members = []types.ValidType{ty.ElemType}
+		case *types.ErrorType:
+			members = append([]types.ValidType{ty.ResultType}, ty.Errors()...)
+		case *types.ExplicitType:
+			members = []types.ValidType{ty.DataType}
+		}
+	}
+
+	for _, member := range members {
+		memberInfo = append(memberInfo, describeType(member, seen))
+	}
+
+	return makeTypeInfo(dataType.String(), typeKind(dataType), fields, memberInfo, params, returns)
+}
+
+// Describes a member of an enum by its name, with the type of the data it holds as its only member, if it has any
+func describeEnumMember(name string, dataType types.ValidType, seen map[types.ValidType]bool) values.RuntimeValue {
+	members := []values.RuntimeValue{}
+	switch ty := dataType.(*types.Type).DataType.(type) {
+	case *types.ExplicitType:
+		members = append(members, describeType(ty.DataType, seen))
+	case *types.TupleStruct, *types.Struct:
+		members = append(members, describeType(ty, seen))
+	}
+	return makeTypeInfo(name, "enum member", []values.RuntimeValue{}, members, []values.RuntimeValue{}, values.MakeNull())
+}
+
+func makeTypeInfo(name, kind string, fields, members, params []values.RuntimeValue, returns values.RuntimeValue) values.RuntimeValue {
+	return &values.StructLiteral{
+		Name: registry.TypeInfo.Name,
+		Members: []values.StructField{
+			{Name: "name", Value: values.MakeString(name)},
+			{Name: "kind", Value: values.MakeString(kind)},
+			{Name: "fields", Value: typeInfoList("fields", fields)},
+			{Name: "members", Value: typeInfoList("members", members)},
+			{Name: "params", Value: typeInfoList("params", params)},
+			{Name: "returns", Value: returns},
+		},
+		BaseValue: values.BaseValue{DataType: registry.TypeInfo},
+	}
+}
+
+func describeField(name string, dataType types.ValidType, seen map[types.ValidType]bool) values.RuntimeValue {
+	return &values.StructLiteral{
+		Name: registry.TypeField.Name,
+		Members: []values.StructField{
+			{Name: "name", Value: values.MakeString(name)},
+			{Name: "type", Value: describeType(dataType, seen)},
+		},
+		BaseValue: values.BaseValue{DataType: registry.TypeField},
+	}
+}
+
+// Makes the value of one of TypeInfo's list fields
+func typeInfoList(field string, elements []values.RuntimeValue) values.RuntimeValue {
+	listType, _ := registry.TypeInfo.Field(field)
+	return &values.ListLiteral{Elements: elements, BaseValue: values.BaseValue{DataType: listType.Type}}
+}
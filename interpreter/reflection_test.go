@@ -0,0 +1,25 @@
+package interpreter
+
+import "testing"
+
+func TestTypeofRendersDefaultParameters(t *testing.T) {
+	expectOutput(t, `
+		fn greet(name: string, greeting: string = "hi") { print(greeting + name) }
+		print(typeof(greet).name)`, "fn greet(name: string, greeting: string = ...)\n")
+}
+
+func TestTypeofDescribesEnumMembersByName(t *testing.T) {
+	expectOutput(t, `
+		enum Colour { Red, Rgb(int, int, int) }
+		print(typeof(Colour).members[0].name)
+		print(typeof(Colour).members[0].kind)
+		print(typeof(Colour).members[1].name)
+		print(typeof(Colour).members[1].members[0].kind)`, "Red\nenum member\nRgb\ntuple struct\n")
+}
+
+func TestTypeofDescribesTupleStructFields(t *testing.T) {
+	expectOutput(t, `
+		struct Pair(int, string)
+		print(typeof(Pair(1, "a")).fields[1].name)
+		print(typeof(Pair(1, "a")).fields[1].type.name)`, "1\nstring\n")
+}
@@ -3,17 +3,19 @@ package interpreter
 import (
 	"fmt"
 	"math"
-	"os"
 
 	"github.com/gearsdatapacks/libra/errors"
 	"github.com/gearsdatapacks/libra/interpreter/environment"
 	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
 	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
 func Register() {
 	registerOperators()
 	registerBuiltins()
+	registerJsonModule()
 }
 
 // func extractValues[T any](vals ...values.RuntimeValue) []T {
@@ -206,13 +208,13 @@ func registerOperators() {
 			if isAInt {
 				valueA = float64(aInt.Value)
 			} else {
-				valueA = a.(*values.FloatLiteral).Value
+				valueA = extractNumericValue(a)
 			}
 
 			if isBInt {
 				valueB = float64(bInt.Value)
 			} else {
-				valueB = b.(*values.FloatLiteral).Value
+				valueB = extractNumericValue(b)
 			}
 
 			return values.MakeBoolean(valueA > valueB)
@@ -231,13 +233,13 @@ func registerOperators() {
 			if isAInt {
 				valueA = float64(aInt.Value)
 			} else {
-				valueA = a.(*values.FloatLiteral).Value
+				valueA = extractNumericValue(a)
 			}
 
 			if isBInt {
 				valueB = float64(bInt.Value)
 			} else {
-				valueB = b.(*values.FloatLiteral).Value
+				valueB = extractNumericValue(b)
 			}
 
 			return values.MakeBoolean(valueA >= valueB)
@@ -256,13 +258,13 @@ func registerOperators() {
 			if isAInt {
 				valueA = float64(aInt.Value)
 			} else {
-				valueA = a.(*values.FloatLiteral).Value
+				valueA = extractNumericValue(a)
 			}
 
 			if isBInt {
 				valueB = float64(bInt.Value)
 			} else {
-				valueB = b.(*values.FloatLiteral).Value
+				valueB = extractNumericValue(b)
 			}
 
 			return values.MakeBoolean(valueA < valueB)
@@ -281,13 +283,13 @@ func registerOperators() {
 			if isAInt {
 				valueA = float64(aInt.Value)
 			} else {
-				valueA = a.(*values.FloatLiteral).Value
+				valueA = extractNumericValue(a)
 			}
 
 			if isBInt {
 				valueB = float64(bInt.Value)
 			} else {
-				valueB = b.(*values.FloatLiteral).Value
+				valueB = extractNumericValue(b)
 			}
 
 			return values.MakeBoolean(valueA <= valueB)
@@ -342,6 +344,8 @@ func registerOperators() {
 		},
 	)
 
+	RegisterUnaryOperator("<-", receive)
+
 	RegisterUnaryOperator("-", func(value values.RuntimeValue, _ bool, env *environment.Environment) values.RuntimeValue {
 		if intValue, isInt := value.(*values.IntegerLiteral); isInt {
 			intVal := intValue.Value
@@ -383,8 +387,7 @@ func registerOperators() {
 			return values.MakeBoolean(!value.Truthy())
 		}
 		if isError(value) {
-			fmt.Println(value.ToString())
-			os.Exit(1)
+			errors.LogError(errorMessage(value, env))
 		}
 		return value
 	})
@@ -398,12 +401,14 @@ func registerOperators() {
 		return value
 	})
 
+	// Pointers to variables, fields and elements are made by evaluateUnaryOperation, as they need the location
 	RegisterUnaryOperator("&", func(value values.RuntimeValue, _ bool, env *environment.Environment) values.RuntimeValue {
-		return values.MakePointer(value)
+		return values.MakePointer(&values.TemporaryLocation{Value: value}, &types.Pointer{DataType: value.Type()})
 	})
 
+	// The value isn't copied, so fields can be assigned through it
 	RegisterUnaryOperator("*", func(value values.RuntimeValue, _ bool, env *environment.Environment) values.RuntimeValue {
-		return value.(*values.Pointer).Value.Copy()
+		return value.(*values.Pointer).Target.Load()
 	})
 }
 
@@ -415,6 +420,38 @@ func isError(value values.RuntimeValue) bool {
 	return errorType.Valid(value.Type())
 }
 
+// Builtin errors have no declared methods, so their error method is made on demand
+func builtinErrorMethod(err *values.Error, manager *modules.ModuleManager) *values.FunctionValue {
+	return &values.FunctionValue{
+		Name:       "error",
+		Parameters: []values.Parameter{},
+		Env:        manager.Env,
+		Manager:    manager,
+		Body:       []ast.Statement{&ast.ReturnStatement{Value: &ast.StringLiteral{Value: err.Msg}}},
+		BaseValue:  values.BaseValue{DataType: types.ErrorInterface.Members["error"]},
+	}
+}
+
+// Gets the message of an error, calling the error method of user defined errors
+func errorMessage(value values.RuntimeValue, env *environment.Environment) string {
+	if err, isRuntimeErr := value.(*values.Error); isRuntimeErr {
+		return err.Msg
+	}
+
+	method := environment.GetMethod("error", value.Type())
+	if method == nil {
+		return value.ToString()
+	}
+
+	bound := *method
+	bound.This = value
+	result := callFunction(&bound, []values.RuntimeValue{}, nil, &modules.ModuleManager{Env: env})
+	if message, ok := result.(*values.StringLiteral); ok {
+		return message.Value
+	}
+	return result.ToString()
+}
+
 type builtin func([]values.RuntimeValue, *environment.Environment) values.RuntimeValue
 
 var builtins = map[string]builtin{}
@@ -428,4 +465,14 @@ func registerBuiltins() {
 	builtins["parse_float"] = parse_float
 	builtins["read_file"] = read_file
 	builtins["write_file"] = write_file
+	builtins["get_env"] = get_env
+	builtins["run_command"] = run_command
+	builtins["wrap_error"] = wrap_error
+	builtins["unwrap_error"] = unwrap_error
+	builtins["map"] = map_list
+	builtins["filter"] = filter
+	builtins["reduce"] = reduce
+	builtins["sort_by"] = sort_by
+	builtins["any"] = any_of
+	builtins["all"] = all_of
 }
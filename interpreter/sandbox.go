@@ -0,0 +1,274 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/interpreter/values"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
+)
+
+// Limits restricts the resources a program can use. A limit of 0 means unlimited
+type Limits struct {
+	// The maximum number of statements and expressions evaluated
+	MaxSteps int
+	// The maximum number of nested function calls, not counting tail calls.
+	// Calls use the Go stack, so 0 means DefaultMaxCallDepth rather than unlimited, and a negative depth is unlimited
+	MaxCallDepth int
+	// The maximum total number of list elements, map entries and string bytes created
+	MaxAllocation int
+}
+
+// The call depth allowed when Limits doesn't set one, deep enough for any reasonable program
+// while stopping runaway recursion before it exhausts the Go stack
+const DefaultMaxCallDepth = 10_000
+
+// Every evaluation has its own sandbox, shared by every goroutine it spawns.
+// It's kept in the environment, so evaluations running at the same time don't affect each other
+type sandbox struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	limits    Limits
+	policy    permissions.Policy
+	steps     atomic.Int64
+	allocated atomic.Int64
+	// Set once ctx is done, so each step can check for it without locking the context
+	stopped atomic.Bool
+
+	mu sync.Mutex
+	// The first error raised by a spawned goroutine, or limit it exceeded
+	err error
+	// The number of goroutines running the program, and how many of them are blocked on a channel
+	tasks   int
+	waiting int
+	// Signalled whenever a channel operation happens or the program is stopped, to wake up blocked goroutines
+	wake *sync.Cond
+}
+
+// Panicked with to unwind the interpreter once a limit is exceeded
+type limitExceeded struct {
+	err error
+}
+
+// Evaluates a program within the given limits and with the given permissions, stopping when ctx is cancelled.
+// Exceeding a limit returns an errors.LanguageError whose ErrorType says which limit was hit,
+// and runtime errors are returned as an errors.RuntimeError, rather than exiting
+func EvaluateSandboxed(ctx context.Context, manager *modules.ModuleManager, limits Limits, policy permissions.Policy) (result values.RuntimeValue, err error) {
+	if limits.MaxCallDepth == 0 {
+		limits.MaxCallDepth = DefaultMaxCallDepth
+	}
+	s := &sandbox{limits: limits, policy: policy, tasks: 1}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.wake = sync.NewCond(&s.mu)
+	enterSandbox(manager, s)
+
+	// Goroutines blocked on a channel wait for the program to stop too
+	go func() {
+		<-s.ctx.Done()
+		s.stopped.Store(true)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.wake.Broadcast()
+	}()
+
+	defer func() {
+		s.cancel()
+
+		if r := recover(); r != nil {
+			result = nil
+			err = failure(r)
+		}
+	}()
+
+	result = evaluateProgram(manager)
+	// A spawned goroutine can fail after the last point the program checked
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return result, nil
+}
+
+// Standard modules are shared by every program, so they never hold a sandbox.
+// Their functions take on the sandbox of whatever calls them
+func enterSandbox(manager *modules.ModuleManager, s *sandbox) {
+	if manager.Std {
+		return
+	}
+	manager.Env.Sandbox = s
+	for _, mod := range manager.Imported {
+		enterSandbox(mod, s)
+	}
+}
+
+func sandboxOf(env *environment.Environment) *sandbox {
+	s, _ := env.Sandbox.(*sandbox)
+	return s
+}
+
+// The permissions of the evaluation a scope is part of, or the default ones outside of an evaluation
+func policyOf(env *environment.Environment) permissions.Policy {
+	if s := sandboxOf(env); s != nil {
+		return s.policy
+	}
+	return permissions.Default()
+}
+
+// The error a program stops with, given what the interpreter panicked with.
+// Must be called while recovering, so that a bug in the interpreter is reported with where it happened
+func failure(r any) error {
+	switch r := r.(type) {
+	case limitExceeded:
+		return r.err
+	case errors.RuntimeError:
+		return r
+	}
+	return errors.RuntimeError{Message: fmt.Sprintf("Internal error: %v\n%s", r, debug.Stack())}
+}
+
+func exceedLimit(kind, message string, nodes ...ast.Node) {
+	panic(limitExceeded{err: errors.LimitError(kind, message, nodes...)})
+}
+
+// Stops every other goroutine once one of them fails
+func (s *sandbox) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+	s.cancel()
+}
+
+// Recovers from a runtime error or a limit being exceeded in a spawned goroutine,
+// so that the whole program stops with the same error
+func (s *sandbox) recoverSpawned() {
+	if r := recover(); r != nil {
+		s.fail(failure(r))
+	}
+}
+
+// Returns a channel which is closed once the program should stop
+func cancelled(env *environment.Environment) <-chan struct{} {
+	if s := sandboxOf(env); s != nil {
+		return s.ctx.Done()
+	}
+	return nil
+}
+
+func checkCancelled(env *environment.Environment, nodes ...ast.Node) {
+	s := sandboxOf(env)
+	if s == nil || s.ctx.Err() == nil {
+		return
+	}
+
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+	if err != nil {
+		panic(limitExceeded{err: err})
+	}
+
+	if s.ctx.Err() == context.DeadlineExceeded {
+		exceedLimit(errors.TIMEOUT, "Execution timed out", nodes...)
+	}
+	exceedLimit(errors.TIMEOUT, "Execution was cancelled", nodes...)
+}
+
+func step(node ast.Node, env *environment.Environment) {
+	s := sandboxOf(env)
+	if s == nil {
+		return
+	}
+
+	if s.stopped.Load() {
+		checkCancelled(env, node)
+	}
+
+	maxSteps := s.limits.MaxSteps
+	if maxSteps <= 0 {
+		return
+	}
+	if steps := s.steps.Add(1); steps > int64(maxSteps) {
+		exceedLimit(errors.STEP_LIMIT, fmt.Sprintf("Exceeded the limit of %d evaluation steps", maxSteps), node)
+	}
+}
+
+func checkCallDepth(scope *environment.Environment, node ast.Node) {
+	s := sandboxOf(scope)
+	if s == nil {
+		return
+	}
+
+	maxDepth := s.limits.MaxCallDepth
+	if maxDepth > 0 && scope.CallDepth() > maxDepth {
+		message := fmt.Sprintf("Stack overflow: exceeded the maximum call depth of %d\n%s", maxDepth, backtrace(scope))
+		// Functions called by the interpreter itself, such as error methods, have no call site
+		if node == nil {
+			exceedLimit(errors.CALL_DEPTH_LIMIT, message)
+		}
+		exceedLimit(errors.CALL_DEPTH_LIMIT, message, node)
+	}
+}
+
+func allocate(size int, node ast.Node, env *environment.Environment) {
+	s := sandboxOf(env)
+	if s == nil {
+		return
+	}
+
+	allocated := s.allocated.Add(int64(size))
+	maxAllocation := s.limits.MaxAllocation
+	if maxAllocation > 0 && allocated > int64(maxAllocation) {
+		exceedLimit(errors.MEMORY_LIMIT, fmt.Sprintf("Exceeded the allocation limit of %d", maxAllocation), node)
+	}
+}
+
+// The size a newly created value counts towards the allocation limit
+func sizeOf(value values.RuntimeValue) int {
+	switch value := value.(type) {
+	case *values.StringLiteral:
+		return len(value.Value)
+	case *values.ListLiteral:
+		return len(value.Elements)
+	case *values.MapLiteral:
+		return len(value.Elements)
+	default:
+		return 0
+	}
+}
+
+// The size of a newly created value and every collection inside it
+func nestedSizeOf(value values.RuntimeValue) int {
+	size := sizeOf(value)
+	switch value := value.(type) {
+	case *values.ListLiteral:
+		for _, element := range value.Elements {
+			size += nestedSizeOf(element)
+		}
+	case *values.MapLiteral:
+		for _, element := range value.Elements {
+			size += nestedSizeOf(element.Key) + nestedSizeOf(element.Value)
+		}
+	}
+	return size
+}
+
+// Counts how much a collection grows by while it is modified
+func allocateGrowth(collection values.RuntimeValue, node ast.Node, env *environment.Environment, modify func() values.RuntimeValue) values.RuntimeValue {
+	before := sizeOf(collection)
+	result := modify()
+	if growth := sizeOf(collection) - before; growth > 0 {
+		allocate(growth, node, env)
+	}
+	return result
+}
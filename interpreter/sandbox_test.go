@@ -0,0 +1,140 @@
+package interpreter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/errors"
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
+	typechecker "github.com/gearsdatapacks/libra/type_checker"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+// Expects a program to stop because it exceeded a limit of the given kind
+func expectLimit(t *testing.T, err error, kind string) {
+	t.Helper()
+	if langErr, ok := err.(errors.LanguageError); !ok || langErr.ErrorType != kind {
+		t.Errorf("expected a %s, got %v", kind, err)
+	}
+}
+
+func TestInterpreterBugIsReturned(t *testing.T) {
+	manager := load(t, `print(1)`)
+	// A tree the type checker would never produce, so the interpreter dereferences nil
+	call := manager.Files[0].Ast.Body[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionCall)
+	call.Args[0] = (*ast.IntegerLiteral)(nil)
+
+	_, err := EvaluateSandboxed(context.Background(), manager, Limits{}, permissions.Default())
+	if err == nil || !strings.HasPrefix(err.Error(), "Internal error") {
+		t.Errorf("expected an internal error, got %v", err)
+	}
+}
+
+func TestImportedModuleRunsUnderEachEvaluationsLimits(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.lb"), []byte(`import "lib.lb"`), 0666)
+	os.WriteFile(filepath.Join(dir, "lib.lb"), []byte(`
+		var i = 0
+		while i < 100 { i += 1 }`), 0666)
+
+	if err := evaluateFile(t, filepath.Join(dir, "main.lb"), Limits{}); err != nil {
+		t.Fatal(err)
+	}
+	expectLimit(t, evaluateFile(t, filepath.Join(dir, "main.lb"), Limits{MaxSteps: 50}), errors.STEP_LIMIT)
+}
+
+// Loads a program from a file, so it can import other modules, and runs it with the given limits
+func evaluateFile(t *testing.T, file string, limits Limits) error {
+	t.Helper()
+	manager, err := modules.NewManager(file, permissions.Default(), symbols.New(), environment.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := typechecker.TypeCheck(manager); err != nil {
+		t.Fatal(err)
+	}
+	_, err = EvaluateSandboxed(context.Background(), manager, limits, permissions.Default())
+	return err
+}
+
+func TestGrowingMapsCountTowardsAllocationLimit(t *testing.T) {
+	// The values are structs declared by each program, as reading a field marks the field's type as
+	// constant, and types like int are shared between programs
+	sources := map[string]string{
+		"index assignment": `
+			struct Entry { n: int }
+			var m: {int: Entry} = {0: Entry { n: 0 }}
+			var i = 0
+			while i < 1000 { m[i] = Entry { n: i }; i += 1 }`,
+		"pointer": `
+			struct Entry { n: int }
+			var m: {int: Entry} = {0: Entry { n: 0 }}
+			var i = 0
+			while i < 1000 { const p = &m[i]; *p = Entry { n: i }; i += 1 }`,
+	}
+
+	for name, source := range sources {
+		_, err := runSandboxed(t, source, Limits{MaxAllocation: 500}, permissions.Default())
+		if langErr, ok := err.(errors.LanguageError); !ok || langErr.ErrorType != errors.MEMORY_LIMIT {
+			t.Errorf("%s: expected a %s, got %v", name, errors.MEMORY_LIMIT, err)
+		}
+	}
+}
+
+func TestDecodedJsonCountsTowardsAllocationLimit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.lb")
+	os.WriteFile(file, []byte(`
+		import "std:json"
+		const text = "[[1, 2, 3, 4, 5], [6, 7, 8, 9, 10], [11, 12, 13, 14, 15]]"
+		var i = 0
+		while i < 100 { const decoded = json.decode[int[][]](text); i += 1 }`), 0666)
+
+	expectLimit(t, evaluateFile(t, file, Limits{MaxAllocation: 500}), errors.MEMORY_LIMIT)
+}
+
+func TestListSlicesCountTowardsAllocationLimit(t *testing.T) {
+	_, err := runSandboxed(t, `
+		var xs = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+		var i = 0
+		while i < 100 { const s = xs[1:]; i += 1 }`, Limits{MaxAllocation: 500}, permissions.Default())
+	expectLimit(t, err, errors.MEMORY_LIMIT)
+}
+
+func TestDeepRecursionIsStackOverflow(t *testing.T) {
+	source := `
+		fn count(n: int): int {
+			if n < 1 { return 0 }
+			return 1 + count(n - 1)
+		}
+		print(count(100_000))`
+
+	// Without a limit set, the default depth still stops recursion before it exhausts the Go stack
+	for _, limits := range []Limits{{MaxCallDepth: 100}, {}} {
+		_, err := runSandboxed(t, source, limits, permissions.Default())
+		expectLimit(t, err, errors.CALL_DEPTH_LIMIT)
+		if err != nil && !strings.Contains(err.Error(), "Stack overflow") {
+			t.Errorf("expected a stack overflow, got %v", err)
+		}
+	}
+}
+
+func TestTailCallsDontCountTowardsCallDepth(t *testing.T) {
+	output, err := runSandboxed(t, `
+		fn count(n: int, total: int): int {
+			if n < 1 { return total }
+			return count(n - 1, total + 1)
+		}
+		print(count(1000, 0))`, Limits{MaxCallDepth: 100}, permissions.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "1000\n" {
+		t.Errorf("expected output %q, got %q", "1000\n", output)
+	}
+}
@@ -0,0 +1,40 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+)
+
+// How many calls at each end of the stack are shown when it overflows
+const backtraceLength = 5
+
+// Lists the calls leading to a function scope, most recent first, leaving out the middle of a long stack
+func backtrace(scope *environment.Environment) string {
+	calls := []*environment.Environment{}
+	for function := scope; function != nil; function = function.Caller.EnclosingFunction() {
+		calls = append(calls, function)
+	}
+
+	omitted := 0
+	if len(calls) > 2*backtraceLength {
+		omitted = len(calls) - 2*backtraceLength
+		calls = append(calls[:backtraceLength:backtraceLength], calls[len(calls)-backtraceLength:]...)
+	}
+
+	lines := []string{}
+	for i, function := range calls {
+		if omitted != 0 && i == backtraceLength {
+			lines = append(lines, fmt.Sprintf("  ... %d more calls", omitted))
+		}
+
+		line := "  in " + function.Function
+		if function.CallSite != nil {
+			token := function.CallSite.GetToken()
+			line += fmt.Sprintf(", called at line %d, column %d", token.Line, token.Column)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -16,11 +16,16 @@ func evaluateVariableDeclaration(varDec *ast.VariableDeclaration, manager *modul
 	var value values.RuntimeValue
 
 	if varDec.Value == nil {
-		value = values.GetZeroValue(varDec.DataType.String())
+		value = values.GetZeroValue(varDec.DataType.GetType())
 	} else {
 		value = evaluateExpression(varDec.Value, manager)
 	}
 
+	if varDec.Pattern != nil {
+		declarePattern(varDec.Pattern, value, manager)
+		return value
+	}
+
 	return manager.Env.DeclareVariable(varDec.Name, varDec.DataType.GetType(), value)
 }
 
@@ -28,29 +33,35 @@ func registerFunctionDeclaration(funcDec *ast.FunctionDeclaration, manager *modu
 	params := []values.Parameter{}
 
 	for _, param := range funcDec.Parameters {
+		paramType := param.Type.GetType()
+		if param.Variadic {
+			paramType = &types.ListLiteral{ElemType: paramType}
+		}
+
 		params = append(params, values.Parameter{
-			Name: param.Name,
-			Type: param.Type.GetType(),
+			Name:     param.Name,
+			Type:     paramType,
+			Default:  param.Default,
+			Variadic: param.Variadic,
 		})
 	}
 
 	functionType := funcDec.GetType().(*types.Function)
+	// Calls copy the function's manager, so it gets one of its own which nothing changes,
+	// as spawned calls can be copying it while the module carries on running
+	declaredIn := *manager
 
 	fn := &values.FunctionValue{
 		Name:       funcDec.Name,
 		Parameters: params,
 		Env:        manager.Env,
-		Manager:    manager,
+		Manager:    &declaredIn,
 		Body:       funcDec.Body,
 		BaseValue:  values.BaseValue{DataType: functionType},
 	}
 
 	if funcDec.MethodOf != nil {
-		parentType := funcDec.MethodOf.GetType()
-		functionType.MethodOf = parentType
-
-		types.AddMethod(funcDec.Name, functionType)
-		environment.AddMethod(funcDec.Name, fn)
+		environment.AddMethod(fn)
 		return fn
 	} else {
 		if funcDec.IsExport() {
@@ -61,49 +72,117 @@ func registerFunctionDeclaration(funcDec *ast.FunctionDeclaration, manager *modu
 }
 
 func evaluateReturnStatement(ret *ast.ReturnStatement, manager *modules.ModuleManager) values.RuntimeValue {
-	value := evaluateExpression(ret.Value, manager)
+	value, tailCall := evaluateTail(ret.Value, manager)
 	functionScope := manager.Env.FindFunctionScope()
+
+	// An error propagated with `?` while evaluating the value is returned instead
+	if functionScope.ReturnValue != nil {
+		return functionScope.ReturnValue
+	}
+
+	if tailCall != nil {
+		// Deferred calls have to run after the returned call, so it can't wait until the function has exited
+		if functionScope.HasDeferred() {
+			value = callFunction(tailCall.Function, tailCall.Args, tailCall.CallSite, manager)
+		} else {
+			functionScope.TailCall = tailCall
+			value = values.MakeNull()
+		}
+	}
+
 	functionScope.ReturnValue = value
 	return value
 }
 
+// Evaluates an expression in tail position, where a call to a function value is returned rather than made,
+// so the caller can make it once the current function has exited
+func evaluateTail(expr ast.Expression, manager *modules.ModuleManager) (values.RuntimeValue, *environment.TailCall) {
+	switch expression := expr.(type) {
+	case *ast.FunctionCall:
+		if callsFunctionValue(expression, manager) {
+			step(expression, manager.Env)
+			function, args := evaluateCall(expression, manager)
+			return nil, &environment.TailCall{Function: function, Args: args, CallSite: expression}
+		}
+
+	case *ast.IfExpression:
+		step(expression, manager.Env)
+		if body, ok := selectBranch(expression.Statement, manager); ok {
+			return evaluateBody(body, manager, true)
+		}
+		return values.MakeNull(), nil
+	}
+
+	return evaluateExpression(expr, manager), nil
+}
+
+// Deferred calls have their arguments evaluated straight away, but are only called once the function exits
+func evaluateDeferStatement(deferStmt *ast.DeferStatement, manager *modules.ModuleManager) values.RuntimeValue {
+	manager.Env.FindFunctionScope().Defer(prepareFunctionCall(deferStmt.Call, manager, true))
+	return values.MakeNull()
+}
+
 func evaluateIfStatement(ifStatement *ast.IfStatement, manager *modules.ModuleManager) values.RuntimeValue {
+	if body, ok := selectBranch(ifStatement, manager); ok {
+		return evaluateBlock(body, manager)
+	}
+	return values.MakeNull()
+}
+
+// Evaluates the conditions of an if statement, giving the body of the branch to run, if any
+func selectBranch(ifStatement *ast.IfStatement, manager *modules.ModuleManager) ([]ast.Statement, bool) {
 	condition := evaluateExpression(ifStatement.Condition, manager)
 
 	if !condition.Truthy() {
-		if ifStatement.Else == nil {
-			return values.MakeNull()
-		}
 		if elseStatement, isElse := ifStatement.Else.(*ast.ElseStatement); isElse {
-			return evaluateElseStatement(elseStatement, manager)
+			return elseStatement.Body, true
 		}
 		if nextIf, isIf := ifStatement.Else.(*ast.IfStatement); isIf {
-			return evaluateIfStatement(nextIf, manager)
+			return selectBranch(nextIf, manager)
 		}
+		return nil, false
 	}
 
-	newScope := environment.NewChild(manager.Env, environment.GENERIC_SCOPE)
-	manager.EnterEnv(newScope)
-
-	for _, statement := range ifStatement.Body {
-		evaluate(statement, manager)
-	}
+	return ifStatement.Body, true
+}
 
-	manager.ExitEnv()
+func evaluateElseStatement(elseStatement *ast.ElseStatement, manager *modules.ModuleManager) values.RuntimeValue {
+	return evaluateBlock(elseStatement.Body, manager)
+}
 
-	return values.MakeNull()
+// Evaluates a block of code in a new scope, giving the value of its final expression, or null
+func evaluateBlock(body []ast.Statement, manager *modules.ModuleManager) values.RuntimeValue {
+	result, _ := evaluateBody(body, manager, false)
+	return result
 }
 
-func evaluateElseStatement(elseStatement *ast.ElseStatement, manager *modules.ModuleManager) values.RuntimeValue {
+// Like evaluateBlock, but if tail is set, the final expression is in tail position and may give a call to make instead
+func evaluateBody(body []ast.Statement, manager *modules.ModuleManager, tail bool) (values.RuntimeValue, *environment.TailCall) {
 	newScope := environment.NewChild(manager.Env, environment.GENERIC_SCOPE)
 	manager.EnterEnv(newScope)
 
-	for _, statement := range elseStatement.Body {
-		evaluate(statement, manager)
+	var result values.RuntimeValue = values.MakeNull()
+	var tailCall *environment.TailCall
+	for i, statement := range body {
+		exprStmt, isExpression := statement.(*ast.ExpressionStatement)
+		if tail && isExpression && i == len(body)-1 {
+			visit(statement, manager)
+			result, tailCall = evaluateTail(exprStmt.Expression, manager)
+			break
+		}
+
+		value := evaluate(statement, manager)
+		if manager.Env.HasReturned() {
+			break
+		}
+
+		if isExpression && i == len(body)-1 {
+			result = value
+		}
 	}
 	manager.ExitEnv()
 
-	return values.MakeNull()
+	return result, tailCall
 }
 
 func evaluateWhileLoop(while *ast.WhileLoop, manager *modules.ModuleManager) values.RuntimeValue {
@@ -113,8 +192,14 @@ func evaluateWhileLoop(while *ast.WhileLoop, manager *modules.ModuleManager) val
 
 		for _, statement := range while.Body {
 			evaluate(statement, manager)
+			if manager.Env.HasReturned() {
+				break
+			}
 		}
 		manager.ExitEnv()
+		if manager.Env.HasReturned() {
+			break
+		}
 	}
 
 	return values.MakeNull()
@@ -132,8 +217,14 @@ func evaluateForLoop(forLoop *ast.ForLoop, manager *modules.ModuleManager) value
 
 		for _, statement := range forLoop.Body {
 			evaluate(statement, manager)
+			if manager.Env.HasReturned() {
+				break
+			}
 		}
 		manager.ExitEnv()
+		if manager.Env.HasReturned() {
+			break
+		}
 		evaluate(forLoop.Update, manager)
 	}
 
@@ -192,11 +283,11 @@ func evaluateUnitStructDeclaration(structDecl *ast.UnitStructDeclaration, manage
 
 func evaluateEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.ModuleManager) values.RuntimeValue {
 	if enumDec.IsUnion {
-		for _, dataType := range enumDec.GetType().(*types.Union).Types {
+		for i, dataType := range enumDec.GetType().(*types.Union).Types {
 			if unitType, ok := dataType.(*types.Type).DataType.(*types.UnitStruct); ok {
 				unit := values.MakeUnitStruct(unitType.Name, unitType)
 				manager.Env.DeclareVariable(unit.Name, unitType, unit)
-				if enumDec.Members[unitType.Name].Exported {
+				if enumDec.Members[i].Exported {
 					manager.Env.Exports[unit.Name] = unit
 				}
 			}
@@ -204,14 +295,15 @@ func evaluateEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Modu
 
 		return values.MakeNull()
 	} else {
+		enumType := enumDec.GetType().(*types.Enum)
 		members := map[string]values.RuntimeValue{}
 
-		for name, member := range enumDec.GetType().(*types.Enum).Types {
-			if unitType, ok := member.DataType.(*types.Type).DataType.(*types.UnitStruct); ok {
-				unit := values.MakeUnitStruct(name, unitType)
+		for name := range enumType.Types {
+			if unit := enumMember(enumType, name); unit != nil {
 				members[name] = unit
 			}
 		}
+		registerEnumFunctions(enumType)
 
 		enum := &values.Enum{
 			Name:      enumDec.Name,
@@ -266,7 +358,7 @@ func evaluateInterfaceDeclaration(intDecl *ast.InterfaceDeclaration, manager *mo
 
 		fnType.Parameters = []types.ValidType{}
 		for _, param := range member.Parameters {
-			paramType := typechecker.TypeCheckType(param, manager)
+			paramType := typechecker.TypeCheckType(param.Type, manager)
 
 			fnType.Parameters = append(fnType.Parameters, paramType)
 		}
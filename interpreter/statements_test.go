@@ -0,0 +1,35 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultMethodUsesEachTypesOperators(t *testing.T) {
+	impls := []string{"impl Doubler for int", "impl Doubler for A"}
+	for _, order := range [][]string{impls, {impls[1], impls[0]}} {
+		expectOutput(t, `
+			interface Doubler {
+				double(): int { return this + this }
+			}
+			struct A { n: int }
+			fn (A) add(o: A): int { return 10 }
+			`+strings.Join(order, "\n")+`
+			var n: int = 1
+			print(n.double())
+			print(A { n: 1 }.double())`, "2\n10\n")
+	}
+}
+
+func TestDeferredArgumentsAreCopiedWhenEvaluated(t *testing.T) {
+	expectOutput(t, `
+		fn show(xs: string[]) { print(xs) }
+		fn run() {
+			var xs: string[] = ["a"]
+			defer print(xs)
+			defer show(xs)
+			var i: int = 0
+			xs[i] = "b"
+		}
+		run()`, "[\"a\"]\n[\"a\"]\n")
+}
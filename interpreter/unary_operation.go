@@ -8,6 +8,7 @@ import (
 	"github.com/gearsdatapacks/libra/interpreter/values"
 	"github.com/gearsdatapacks/libra/modules"
 	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
 var unaryOperators = map[string]unOpFn{}
@@ -19,6 +20,10 @@ func RegisterUnaryOperator(op string, operation unOpFn) {
 }
 
 func evaluateUnaryOperation(unOp *ast.UnaryOperation, manager *modules.ModuleManager) values.RuntimeValue {
+	if unOp.Operator == "&" && isAddressable(unOp.Value) {
+		return values.MakePointer(locate(unOp.Value, manager), unOp.GetType())
+	}
+
 	value := evaluateExpression(unOp.Value, manager)
 
 	operation, ok := unaryOperators[unOp.Operator]
@@ -27,5 +32,12 @@ func evaluateUnaryOperation(unOp *ast.UnaryOperation, manager *modules.ModuleMan
 		errors.LogError(errors.DevError(fmt.Sprintf("Operator %q does not exist", unOp.Operator), unOp))
 	}
 
+	if unOp.Operator == "!" && unOp.Postfix {
+		_, isOptional := unOp.Value.GetType().(*types.Optional)
+		if _, isNull := value.(*values.NullLiteral); isOptional && isNull {
+			errors.LogError(fmt.Sprintf("Cannot unwrap %q, it is null", unOp.Value.String()))
+		}
+	}
+
 	return operation(value, unOp.Postfix, manager.Env)
 }
@@ -54,6 +54,9 @@ func (un *UntypedNumber) AutoCast(ty types.ValidType) RuntimeValue {
 }
 
 func (un *UntypedNumber) castTo(ty types.ValidType) RuntimeValue {
+	if optional, ok := ty.(*types.Optional); ok {
+		return un.castTo(optional.DataType)
+	}
 	if _, ok := ty.(*types.FloatLiteral); ok {
 		return MakeFloat(un.Value)
 	}
@@ -178,6 +181,26 @@ func (str *StringLiteral) Copy() RuntimeValue {
 	return &temp
 }
 
+// Strings are indexed by character, rather than by byte
+func (str *StringLiteral) Index(indexValue RuntimeValue) RuntimeValue {
+	chars := []rune(str.Value)
+	index := Expect(indexValue, &types.IntLiteral{}).(*IntegerLiteral).Value
+	if index < -len(chars) || index >= len(chars) {
+		errors.LogError(fmt.Sprintf("Index out of range [%d] with length %d", index, len(chars)))
+	}
+
+	if index < 0 {
+		index += len(chars)
+	}
+	return MakeString(string(chars[index]))
+}
+
+func (str *StringLiteral) Slice(start, end RuntimeValue) RuntimeValue {
+	chars := []rune(str.Value)
+	from, to := sliceBounds(start, end, len(chars))
+	return MakeString(string(chars[from:to]))
+}
+
 type NullLiteral struct {
 	BaseValue
 }
@@ -305,6 +328,44 @@ func (list *ListLiteral) Index(indexValue RuntimeValue) RuntimeValue {
 	return list.Elements[index]
 }
 
+// Slices of arrays are lists, so the type of the result is passed in
+func (list *ListLiteral) Slice(start, end RuntimeValue, dataType types.ValidType) RuntimeValue {
+	from, to := sliceBounds(start, end, len(list.Elements))
+
+	elements := []RuntimeValue{}
+	for _, elem := range list.Elements[from:to] {
+		elements = append(elements, elem.Copy())
+	}
+
+	return &ListLiteral{
+		Elements:  elements,
+		BaseValue: BaseValue{DataType: dataType},
+	}
+}
+
+// Resolves the bounds of a slice, which default to the whole value and count from the end if negative
+func sliceBounds(start, end RuntimeValue, length int) (int, int) {
+	from, to := 0, length
+	if start != nil {
+		from = Expect(start, &types.IntLiteral{}).(*IntegerLiteral).Value
+	}
+	if end != nil {
+		to = Expect(end, &types.IntLiteral{}).(*IntegerLiteral).Value
+	}
+
+	if from < 0 {
+		from += length
+	}
+	if to < 0 {
+		to += length
+	}
+
+	if from < 0 || to > length || from > to {
+		errors.LogError(fmt.Sprintf("Slice bounds out of range [%d:%d] with length %d", from, to, length))
+	}
+	return from, to
+}
+
 func (list *ListLiteral) SetIndex(indexValue RuntimeValue, value RuntimeValue) RuntimeValue {
 	index := indexValue.(*IntegerLiteral).Value
 	indexSize := index
@@ -340,9 +401,15 @@ func (list *ListLiteral) Copy() RuntimeValue {
 	}
 }
 
+type MapElement struct {
+	Key   RuntimeValue
+	Value RuntimeValue
+}
+
 type MapLiteral struct {
 	BaseValue
-	Elements map[RuntimeValue]RuntimeValue
+	// In the order they were inserted
+	Elements []MapElement
 }
 
 // func (maplit *MapLiteral) Type() ValueType {
@@ -354,10 +421,10 @@ func (maplit *MapLiteral) ToString() string {
 
 	elemStrings := []string{}
 
-	for key, value := range maplit.Elements {
-		elemStrings = append(elemStrings, key.ToString())
+	for _, element := range maplit.Elements {
+		elemStrings = append(elemStrings, element.Key.ToString())
 		elemStrings[len(elemStrings)-1] += ": "
-		elemStrings[len(elemStrings)-1] += value.ToString()
+		elemStrings[len(elemStrings)-1] += element.Value.ToString()
 	}
 
 	result += strings.Join(elemStrings, ", ")
@@ -375,8 +442,8 @@ func (maplit *MapLiteral) EqualTo(other RuntimeValue) bool {
 		return false
 	}
 
-	for key, value := range maplit.Elements {
-		if !value.EqualTo(otherMap.Elements[key]) {
+	for _, element := range maplit.Elements {
+		if !element.Value.EqualTo(otherMap.Index(element.Key)) {
 			return false
 		}
 	}
@@ -389,9 +456,9 @@ func (maplit *MapLiteral) Truthy() bool {
 }
 
 func (maplit *MapLiteral) Index(indexValue RuntimeValue) RuntimeValue {
-	for key, value := range maplit.Elements {
-		if key.EqualTo(indexValue) {
-			return value
+	for _, element := range maplit.Elements {
+		if element.Key.EqualTo(indexValue) {
+			return element.Value
 		}
 	}
 
@@ -399,13 +466,13 @@ func (maplit *MapLiteral) Index(indexValue RuntimeValue) RuntimeValue {
 }
 
 func (maplit *MapLiteral) SetIndex(indexValue RuntimeValue, value RuntimeValue) RuntimeValue {
-	for key := range maplit.Elements {
-		if key.EqualTo(indexValue) {
-			maplit.Elements[key] = value
+	for i, element := range maplit.Elements {
+		if element.Key.EqualTo(indexValue) {
+			maplit.Elements[i].Value = value
 			return value
 		}
 	}
-	maplit.Elements[indexValue] = value
+	maplit.Elements = append(maplit.Elements, MapElement{Key: indexValue, Value: value})
 	return value
 }
 
@@ -422,8 +489,10 @@ func (maplit *MapLiteral) Copy() RuntimeValue {
 }
 
 type Parameter struct {
-	Name string
-	Type types.ValidType
+	Name     string
+	Type     types.ValidType
+	Default  ast.Expression
+	Variadic bool
 }
 
 type FunctionValue struct {
@@ -434,6 +503,10 @@ type FunctionValue struct {
 	Manager    any
 	Body       []ast.Statement
 	This       RuntimeValue
+	// Set for functions implemented by the interpreter, such as those of enums
+	Native func(args []RuntimeValue) RuntimeValue
+	// Set for functions which take a type argument (f[T]), giving the function for that type
+	Instantiate func(typeArgument types.ValidType) *FunctionValue
 }
 
 // func (fn *FunctionValue) Type() ValueType {
@@ -476,10 +549,16 @@ func (fn *FunctionValue) Copy() RuntimeValue {
 	return fn
 }
 
+type StructField struct {
+	Name  string
+	Value RuntimeValue
+}
+
 type StructLiteral struct {
 	BaseValue
-	Name    string
-	Members map[string]RuntimeValue
+	Name string
+	// In the order the fields were declared
+	Members []StructField
 }
 
 // func (sl *StructLiteral) Type() ValueType {
@@ -489,10 +568,10 @@ type StructLiteral struct {
 func (sl *StructLiteral) ToString() string {
 	result := "{ "
 
-	for name, expr := range sl.Members {
-		result += name
+	for _, field := range sl.Members {
+		result += field.Name
 		result += ": "
-		result += expr.ToString()
+		result += field.Value.ToString()
 		result += ", "
 	}
 
@@ -511,13 +590,13 @@ func (sl *StructLiteral) EqualTo(value RuntimeValue) bool {
 		return false
 	}
 
-	for name, member := range sl.Members {
-		value, ok := struc.Members[name]
-		if !ok {
+	for _, field := range sl.Members {
+		value := struc.Member(field.Name)
+		if value == nil {
 			return false
 		}
 
-		if !member.EqualTo(value) {
+		if !field.Value.EqualTo(value) {
 			return false
 		}
 	}
@@ -526,24 +605,29 @@ func (sl *StructLiteral) EqualTo(value RuntimeValue) bool {
 }
 
 func (sl *StructLiteral) Member(member string) RuntimeValue {
-	value, ok := sl.Members[member]
-	if !ok {
-		return nil
+	for _, field := range sl.Members {
+		if field.Name == member {
+			return field.Value
+		}
 	}
 
-	return value
+	return nil
 }
 
 func (sl *StructLiteral) SetMember(member string, value RuntimeValue) RuntimeValue {
-	sl.Members[member] = value
+	for i, field := range sl.Members {
+		if field.Name == member {
+			sl.Members[i].Value = value
+		}
+	}
 
 	return value
 }
 
 func (sl *StructLiteral) Copy() RuntimeValue {
-	members := map[string]RuntimeValue{}
-	for name, member := range sl.Members {
-		members[name] = member.Copy()
+	members := []StructField{}
+	for _, field := range sl.Members {
+		members = append(members, StructField{Name: field.Name, Value: field.Value.Copy()})
 	}
 
 	return &StructLiteral{
@@ -693,13 +777,46 @@ func (tv *TupleStructValue) Copy() RuntimeValue {
 	}
 }
 
+// A place a pointer can refer to, such as a variable, struct field or list element
+type Location interface {
+	Load() RuntimeValue
+	Store(RuntimeValue) RuntimeValue
+}
+
+// Locations which hold values, such as the key of a map entry, can't be compared with ==,
+// so they say themselves whether they are the same place as another location
+type comparableLocation interface {
+	SameAs(Location) bool
+}
+
+func SameLocation(a, b Location) bool {
+	if comparable, ok := a.(comparableLocation); ok {
+		return comparable.SameAs(b)
+	}
+	return a == b
+}
+
+// Holds a value which isn't stored anywhere else, such as the result of a function call
+type TemporaryLocation struct {
+	Value RuntimeValue
+}
+
+func (temp *TemporaryLocation) Load() RuntimeValue {
+	return temp.Value
+}
+
+func (temp *TemporaryLocation) Store(value RuntimeValue) RuntimeValue {
+	temp.Value = value.Copy()
+	return temp.Value
+}
+
 type Pointer struct {
 	BaseValue
-	Value RuntimeValue
+	Target Location
 }
 
 func (p *Pointer) Truthy() bool {
-	return p.Value.Truthy()
+	return p.Target.Load().Truthy()
 }
 
 func (p *Pointer) EqualTo(other RuntimeValue) bool {
@@ -707,7 +824,7 @@ func (p *Pointer) EqualTo(other RuntimeValue) bool {
 	if !ok {
 		return false
 	}
-	return p.Value == ptr.Value
+	return SameLocation(p.Target, ptr.Target)
 }
 
 func (p *Pointer) Copy() RuntimeValue {
@@ -715,16 +832,82 @@ func (p *Pointer) Copy() RuntimeValue {
 }
 
 func (p *Pointer) ToString() string {
-	return "&" + p.Value.ToString()
+	return "&" + p.Target.Load().ToString()
+}
+
+// Fields are accessed through the pointer, so they can be modified in place
+func (p *Pointer) Member(member string) RuntimeValue {
+	return p.Target.Load().Member(member)
+}
+
+func (p *Pointer) SetMember(member string, value RuntimeValue) RuntimeValue {
+	return p.Target.Load().SetMember(member, value)
+}
+
+func MakePointer(target Location, dataType types.ValidType) *Pointer {
+	return &Pointer{
+		Target:    target,
+		BaseValue: BaseValue{DataType: dataType},
+	}
 }
 
-func MakePointer(value RuntimeValue) *Pointer {
-	return &Pointer{Value: value}
+// Channels are implemented by the interpreter rather than with Go channels,
+// so it can tell when every goroutine is blocked on one. Their state is guarded by the program's sandbox
+type Channel struct {
+	BaseValue
+	Capacity int
+	Buffer   []RuntimeValue
+	// Operations blocked until the channel can be sent to or received from, in the order they started waiting
+	Senders   []*ChannelOperation
+	Receivers []*ChannelOperation
+}
+
+// One of the operations a blocked goroutine is waiting on, such as a case of a select statement
+type ChannelOperation struct {
+	Waiter *ChannelWaiter
+	Case   int
+	// The value to send, for send operations
+	Value RuntimeValue
+}
+
+type ChannelWaiter struct {
+	// Set once one of its operations has happened
+	Done   bool
+	Chosen int
+	// The value received, for receive operations
+	Received RuntimeValue
+}
+
+func (c *Channel) Truthy() bool {
+	return true
+}
+
+func (c *Channel) EqualTo(other RuntimeValue) bool {
+	channel, ok := other.(*Channel)
+	return ok && channel == c
+}
+
+// Channels are shared rather than copied, so that both ends refer to the same channel
+func (c *Channel) Copy() RuntimeValue {
+	return c
+}
+
+func (c *Channel) ToString() string {
+	return fmt.Sprintf("<%s>", c.DataType.String())
+}
+
+func MakeChannel(capacity int, dataType types.ValidType) *Channel {
+	return &Channel{
+		Capacity:  capacity,
+		BaseValue: BaseValue{DataType: dataType},
+	}
 }
 
 type Error struct {
 	BaseValue
 	Msg string
+	// The error this one wraps, if it was created by adding context to another error
+	Cause RuntimeValue
 }
 
 func (err *Error) ToString() string {
@@ -735,8 +918,16 @@ func (sl *Error) Truthy() bool {
 	return true
 }
 
-func (*Error) EqualTo(value RuntimeValue) bool {
-	return true
+func (err *Error) EqualTo(value RuntimeValue) bool {
+	other, ok := value.(*Error)
+	if !ok || other.Msg != err.Msg {
+		return false
+	}
+
+	if err.Cause == nil || other.Cause == nil {
+		return err.Cause == other.Cause
+	}
+	return err.Cause.EqualTo(other.Cause)
 }
 
 func (e *Error) Copy() RuntimeValue {
@@ -746,7 +937,17 @@ func (e *Error) Copy() RuntimeValue {
 
 func MakeError(msg string) RuntimeValue {
 	return &Error{
-		Msg: msg,
+		Msg:       msg,
+		BaseValue: BaseValue{DataType: types.ErrorInterface},
+	}
+}
+
+// Wraps an error with some context. The message must already include the message of the cause
+func WrapError(msg string, cause RuntimeValue) RuntimeValue {
+	return &Error{
+		Msg:       msg,
+		Cause:     cause,
+		BaseValue: BaseValue{DataType: types.ErrorInterface},
 	}
 }
 
@@ -93,20 +93,43 @@ func Expect(value RuntimeValue, ty types.ValidType) RuntimeValue {
 // 	}
 // }
 
-func GetZeroValue(dataType string) RuntimeValue {
-	switch dataType {
-	case "int":
+func GetZeroValue(dataType types.ValidType) RuntimeValue {
+	switch ty := dataType.(type) {
+	case *types.IntLiteral:
 		return MakeInteger(0)
-	case "float":
+	case *types.FloatLiteral:
 		return MakeFloat(0)
-	case "boolean":
+	case *types.BoolLiteral:
 		return MakeBoolean(false)
-	case "null":
-		return MakeNull()
-	case "string":
+	case *types.StringLiteral:
 		return MakeString("")
+	case *types.ListLiteral:
+		return &ListLiteral{
+			Elements:  []RuntimeValue{},
+			BaseValue: BaseValue{DataType: ty},
+		}
+	case *types.ArrayLiteral:
+		elements := []RuntimeValue{}
+		for i := 0; i < ty.Length; i++ {
+			elements = append(elements, GetZeroValue(ty.ElemType))
+		}
+		return &ListLiteral{
+			Elements:  elements,
+			BaseValue: BaseValue{DataType: ty},
+		}
+	case *types.MapLiteral:
+		return &MapLiteral{
+			Elements:  []MapElement{},
+			BaseValue: BaseValue{DataType: ty},
+		}
+	case *types.Tuple:
+		members := []RuntimeValue{}
+		for _, member := range ty.Members {
+			members = append(members, GetZeroValue(member))
+		}
+		return &TupleValue{Members: members}
 	default:
-		// errors.LogError(errors.DevError(fmt.Sprintf("Cannot create runtime value of type %s", dataType)))
+		// The type checker makes sure any other type accepts null
 		return MakeNull()
 	}
 }
@@ -31,6 +31,9 @@ const (
 	// SEMICOLON
 	COLON
 	QUESTION
+	DOUBLE_QUESTION
+	QUESTION_DOT
+	ELLIPSIS
 
 	EQUALS
 	PLUS_EQUALS
@@ -69,6 +72,8 @@ const (
 	PIPE
 	AMPERSAND
 	ARROW
+	LEFT_ARROW
+	PIPE_RIGHT_ANGLE
 )
 
 var Symbols = map[string]Type{
@@ -83,6 +88,9 @@ var Symbols = map[string]Type{
 	// ";": SEMICOLON,
 	":": COLON,
 	"?": QUESTION,
+	"??": DOUBLE_QUESTION,
+	"?.": QUESTION_DOT,
+	"...": ELLIPSIS,
 
 	"+":  PLUS,
 	"-":  MINUS,
@@ -118,6 +126,8 @@ var Symbols = map[string]Type{
 	"--": DOUBLE_MINUS,
 	"!":  BANG,
 	"->": ARROW,
+	"<-": LEFT_ARROW,
+	"|>": PIPE_RIGHT_ANGLE,
 }
 
 var AssignmentOperator = []Type{
@@ -134,6 +144,7 @@ var PrefixOperator = []Type{
 	BANG,
 	AMPERSAND,
 	STAR,
+	LEFT_ARROW,
 }
 
 var PostfixOperator = []Type{
@@ -147,27 +158,33 @@ var BinOpInfo = map[Type]struct {
 	Precedence       int
 	RightAssociative bool
 }{
-	DOUBLE_AMPERSAND: {Precedence: 1},
-	DOUBLE_PIPE:  {Precedence: 1},
+	PIPE_RIGHT_ANGLE: {Precedence: 0},
 
-	LEFT_ANGLE:       {Precedence: 2},
-	LEFT_ANGLE_EQUALS:    {Precedence: 2},
-	RIGHT_ANGLE:    {Precedence: 2},
-	RIGHT_ANGLE_EQUALS: {Precedence: 2},
-	DOUBLE_EQUALS:           {Precedence: 2},
-	BANG_EQUALS:       {Precedence: 2},
+	LEFT_ARROW: {Precedence: 1},
 
-	DOUBLE_LEFT_ANGLE:  {Precedence: 3},
-	DOUBLE_RIGHT_ANGLE: {Precedence: 3, RightAssociative: true},
+	DOUBLE_AMPERSAND: {Precedence: 2},
+	DOUBLE_PIPE:  {Precedence: 2},
 
-	PLUS:  {Precedence: 4},
-	MINUS: {Precedence: 4},
+	DOUBLE_QUESTION: {Precedence: 3},
 
-	STAR:    {Precedence: 5},
-	SLASH:   {Precedence: 5},
-	PERCENT: {Precedence: 5},
+	LEFT_ANGLE:       {Precedence: 4},
+	LEFT_ANGLE_EQUALS:    {Precedence: 4},
+	RIGHT_ANGLE:    {Precedence: 4},
+	RIGHT_ANGLE_EQUALS: {Precedence: 4},
+	DOUBLE_EQUALS:           {Precedence: 4},
+	BANG_EQUALS:       {Precedence: 4},
 
-	DOUBLE_STAR: {Precedence: 6, RightAssociative: true},
+	DOUBLE_LEFT_ANGLE:  {Precedence: 5},
+	DOUBLE_RIGHT_ANGLE: {Precedence: 5, RightAssociative: true},
+
+	PLUS:  {Precedence: 6},
+	MINUS: {Precedence: 6},
+
+	STAR:    {Precedence: 7},
+	SLASH:   {Precedence: 7},
+	PERCENT: {Precedence: 7},
+
+	DOUBLE_STAR: {Precedence: 8, RightAssociative: true},
 }
 
 func (tokenType Type) Is(opGroup []Type) bool {
@@ -2,15 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/gearsdatapacks/libra/debugger"
 	"github.com/gearsdatapacks/libra/interpreter"
 	"github.com/gearsdatapacks/libra/interpreter/environment"
 	"github.com/gearsdatapacks/libra/lexer"
 	"github.com/gearsdatapacks/libra/modules"
 	"github.com/gearsdatapacks/libra/parser"
+	"github.com/gearsdatapacks/libra/permissions"
 	typechecker "github.com/gearsdatapacks/libra/type_checker"
 	"github.com/gearsdatapacks/libra/type_checker/symbols"
 )
@@ -57,13 +61,17 @@ func repl() {
 			continue
 		}
 
-		result := interpreter.Evaluate(manager)
+		result, err := interpreter.EvaluateSandboxed(context.Background(), manager, interpreter.Limits{}, policy)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
 		fmt.Println(result.ToString())
 	}
 }
 
-func run(file string) {
-	mods, err := modules.NewManager(file, symbols.New(), environment.New())
+func load(file string) *modules.ModuleManager {
+	mods, err := modules.NewManager(file, policy, symbols.New(), environment.New())
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -75,16 +83,87 @@ func run(file string) {
 		os.Exit(1)
 	}
 
-	interpreter.Evaluate(mods)
+	return mods
+}
+
+var (
+	maxSteps     = flag.Int("max-steps", 0, "maximum number of statements and expressions to evaluate (0 for no limit)")
+	maxCallDepth = flag.Int("max-depth", interpreter.DefaultMaxCallDepth, "nested calls allowed before a stack overflow error, not counting tail calls (-1 for no limit)")
+	maxMemory    = flag.Int("max-memory", 0, "maximum total list elements, map entries and string bytes to allocate (0 for no limit)")
+	timeout      = flag.Duration("timeout", 0, "maximum time the program can run for (0 for no limit)")
+)
+
+var (
+	policy   = permissions.Default()
+	allowAll = flag.Bool("allow-all", false, "grant all permissions")
+)
+
+func init() {
+	flag.Var(&policy.Read, "allow-read", "allow reading files, optionally only in a comma separated list of directories")
+	flag.Var(&policy.Write, "allow-write", "allow writing files, optionally only in a comma separated list of directories")
+	flag.BoolVar(&policy.Env, "allow-env", false, "allow accessing environment variables")
+	flag.BoolVar(&policy.Run, "allow-run", false, "allow running commands")
+	flag.BoolVar(&policy.Prompt, "allow-prompt", true, "allow reading from standard input")
+}
+
+func run(file string) {
+	mods := load(file)
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	_, err := interpreter.EvaluateSandboxed(ctx, mods, interpreter.Limits{
+		MaxSteps:      *maxSteps,
+		MaxCallDepth:  *maxCallDepth,
+		MaxAllocation: *maxMemory,
+	}, policy)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	// fmt.Println(result.ToString())
 }
 
+func debug(file string) {
+	debugger.RunTerminal(load(file), policy, os.Stdin, os.Stdout)
+}
+
+func dap() {
+	err := debugger.ServeDAP(policy, os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
 func main() {
 	register()
+	flag.Parse()
+	args := flag.Args()
+
+	if *allowAll {
+		policy = permissions.AllowAll()
+	}
 
-	if len(os.Args) == 1 {
+	if len(args) == 0 {
 		repl()
-	} else {
-		run(os.Args[1])
+		return
+	}
+
+	switch args[0] {
+	case "debug":
+		if len(args) < 2 {
+			fmt.Println("Usage: libra debug <file>")
+			os.Exit(1)
+		}
+		debug(args[1])
+	case "dap":
+		dap()
+	default:
+		run(args[0])
 	}
 }
@@ -1,13 +1,19 @@
 package modules
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"strings"
+	"sync"
 
+	"github.com/gearsdatapacks/libra/errors"
 	"github.com/gearsdatapacks/libra/interpreter/environment"
 	"github.com/gearsdatapacks/libra/lexer"
 	"github.com/gearsdatapacks/libra/parser"
 	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/permissions"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
 	"github.com/gearsdatapacks/libra/type_checker/symbols"
 )
 
@@ -82,24 +88,52 @@ type ModuleManager struct {
 	TypeCheckStage int
 	InterpretStage int
 	Id             int
+	// Whether this is a standard module, which is shared by every program
+	Std bool
 }
 
-var fetchedModules = map[string]*ModuleManager{}
 var id = 0
 
-func NewManager(file string, table *symbols.SymbolTable, env *environment.Environment) (*ModuleManager, error) {
+// Modules implemented by the interpreter, imported with "std:name".
+// Unlike other modules, they are shared by every program
+var stdModules = map[string]*ModuleManager{}
+
+// Guards stdModules and id
+var loadMu sync.Mutex
+
+func baseDir(file string) string {
+	if isDir(file) {
+		return file
+	}
+	return path.Dir(file)
+}
+
+// Loads a program and everything it imports.
+// Modules inside the program's directory can always be imported, anything else requires read permission.
+// Each program gets its own instance of the modules it imports, so programs run at the same time don't share their state
+func NewManager(file string, policy permissions.Policy, table *symbols.SymbolTable, env *environment.Environment) (*ModuleManager, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	loader := &loader{root: baseDir(file), policy: policy, fetched: map[string]*ModuleManager{}}
+	return loader.load(file, table, env)
+}
+
+// The state of loading a single program
+type loader struct {
+	root    string
+	policy  permissions.Policy
+	fetched map[string]*ModuleManager
+}
+
+func (l *loader) load(file string, table *symbols.SymbolTable, env *environment.Environment) (*ModuleManager, error) {
 	id++
 	mods, err := Get(file)
 	if err != nil {
 		return nil, err
 	}
 
-	var basePath string
-	if isDir(file) {
-		basePath = file
-	} else {
-		basePath = path.Dir(file)
-	}
+	basePath := baseDir(file)
 
 	_, name := path.Split(basePath)
 	m := &ModuleManager{
@@ -108,20 +142,38 @@ func NewManager(file string, table *symbols.SymbolTable, env *environment.Enviro
 		Env:         env,
 		Imported:    map[string]*ModuleManager{},
 		Name:        name,
-		Id: id,
+		Id:          id,
 	}
-	fetchedModules[file] = m
+	l.fetched[file] = m
 
 	for _, file := range m.Files {
 		for _, stmt := range file.Ast.Body {
 			if importStmt, ok := stmt.(*ast.ImportStatement); ok {
+				if name, isStd := strings.CutPrefix(importStmt.Module, "std:"); isStd {
+					exports, exists := registry.StdModules[name]
+					if !exists {
+						return nil, errors.ImportError(fmt.Sprintf("Unknown standard module %q", importStmt.Module), importStmt)
+					}
+					modManager := std(name)
+					for name, export := range exports {
+						modManager.SymbolTable.AddExport(name, export, modManager.Id)
+					}
+					m.Imported[importStmt.Module] = modManager
+					continue
+				}
+
+				// Checked before the cache, as the module may have been loaded by an importer inside the program's directory
 				modPath := path.Clean(path.Join(basePath, importStmt.Module))
-				if modManager, loaded := fetchedModules[modPath]; loaded {
+				if !permissions.Contains(l.root, modPath) && !l.policy.CanRead(modPath) {
+					return nil, errors.PermissionError(fmt.Sprintf("Cannot import %q from outside the program's directory without read access (run with --allow-read)", importStmt.Module), importStmt)
+				}
+
+				if modManager, loaded := l.fetched[modPath]; loaded {
 					m.Imported[importStmt.Module] = modManager
 					continue
 				}
 
-				modManager, err := NewManager(modPath, symbols.New(), environment.New())
+				modManager, err := l.load(modPath, symbols.New(), environment.New())
 				if err != nil {
 					return nil, err
 				}
@@ -133,6 +185,33 @@ func NewManager(file string, table *symbols.SymbolTable, env *environment.Enviro
 	return m, nil
 }
 
+// Gets a standard module, creating it the first time so the interpreter can register its values
+func Std(name string) *ModuleManager {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	return std(name)
+}
+
+func std(name string) *ModuleManager {
+	if mod, exists := stdModules[name]; exists {
+		return mod
+	}
+
+	id++
+	mod := &ModuleManager{
+		Name:        name,
+		Files:       []Module{},
+		SymbolTable: symbols.New(),
+		Env:         environment.New(),
+		Imported:    map[string]*ModuleManager{},
+		Id:          id,
+		Std:         true,
+	}
+	stdModules[name] = mod
+	return mod
+}
+
 func NewDetatched(table *symbols.SymbolTable, env *environment.Environment) *ModuleManager {
 	return &ModuleManager{
 		Name: "main",
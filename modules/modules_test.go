@@ -0,0 +1,76 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/permissions"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+// Writes files into a temporary directory, returning its path
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestProgramsGetTheirOwnModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.lb": `import "lib.lb"`,
+		"lib.lb":  `var counter = 0`,
+	})
+
+	first, err := NewManager(filepath.Join(dir, "main.lb"), permissions.Default(), symbols.New(), environment.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewManager(filepath.Join(dir, "main.lb"), permissions.Default(), symbols.New(), environment.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Imported["lib.lb"] == second.Imported["lib.lb"] {
+		t.Error("expected each program to load its own instance of an imported module")
+	}
+}
+
+func TestImportOutsideProgramNeedsReadAccess(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app/main.lb": `import "../lib.lb"`,
+		"lib.lb":      `var counter = 0`,
+	})
+	main := filepath.Join(dir, "app", "main.lb")
+
+	_, err := NewManager(main, permissions.Default(), symbols.New(), environment.New())
+	if err == nil || !strings.Contains(err.Error(), "without read access") {
+		t.Errorf("expected importing from outside the program's directory to be denied, got %v", err)
+	}
+
+	policy := permissions.Default()
+	policy.Read = permissions.Paths{Dirs: []string{dir}}
+	if _, err := NewManager(main, policy, symbols.New(), environment.New()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnknownStandardModule(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"main.lb": `import "std:missing"`})
+
+	_, err := NewManager(filepath.Join(dir, "main.lb"), permissions.Default(), symbols.New(), environment.New())
+	if err == nil || !strings.Contains(err.Error(), "Unknown standard module") {
+		t.Errorf("expected an error importing an unknown standard module, got %v", err)
+	}
+}
@@ -49,6 +49,11 @@ type TypeExpression interface {
 	typeNode()
 }
 
+type Pattern interface {
+	Node
+	patternNode()
+}
+
 type Program struct {
 	Body []Statement
 }
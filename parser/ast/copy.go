@@ -0,0 +1,57 @@
+package ast
+
+import "reflect"
+
+var astPackage = reflect.TypeOf(BaseNode{}).PkgPath()
+
+// Makes a deep copy of part of a tree, so the type checker can annotate it without changing the original.
+// Anything outside the tree, such as the types nodes are annotated with, is shared
+func Copy[T any](node T) T {
+	copied := copyValue(reflect.ValueOf(&node).Elem())
+	return copied.Interface().(T)
+}
+
+func copyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() || value.Type().Elem().PkgPath() != astPackage {
+			return value
+		}
+		result := reflect.New(value.Type().Elem())
+		result.Elem().Set(copyValue(value.Elem()))
+		return result
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		result := reflect.New(value.Type()).Elem()
+		result.Set(copyValue(value.Elem()))
+		return result
+
+	case reflect.Struct:
+		if value.Type().PkgPath() != astPackage {
+			return value
+		}
+		result := reflect.New(value.Type()).Elem()
+		result.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if result.Field(i).CanSet() {
+				result.Field(i).Set(copyValue(value.Field(i)))
+			}
+		}
+		return result
+
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		result := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return result
+	}
+
+	return value
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gearsdatapacks/libra/lexer/token"
+	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
 type BaseExpression struct{}
@@ -111,10 +112,36 @@ func (list *ListLiteral) String() string {
 	return result
 }
 
+// A list built from each element of another, e.g. [x * 2 for x in xs if x > 0]
+type ListComprehension struct {
+	BaseNode
+	BaseExpression
+	Value     Expression
+	Variable  string
+	Iterable  Expression
+	Condition Expression
+}
+
+func (comp *ListComprehension) Type() NodeType { return "ListComprehension" }
+
+func (comp *ListComprehension) String() string {
+	result := fmt.Sprintf("[%s for %s in %s", comp.Value.String(), comp.Variable, comp.Iterable.String())
+	if comp.Condition != nil {
+		result += " if " + comp.Condition.String()
+	}
+	return result + "]"
+}
+
+type MapElement struct {
+	Key   Expression
+	Value Expression
+}
+
 type MapLiteral struct {
 	BaseNode
 	BaseExpression
-	Elements map[Expression]Expression
+	// In the order they were written
+	Elements []MapElement
 }
 
 func (*MapLiteral) Type() NodeType { return "Map" }
@@ -123,10 +150,10 @@ func (maplit *MapLiteral) String() string {
 	result := "{"
 	valueStrings := []string{}
 
-	for key, value := range maplit.Elements {
-		valueStrings = append(valueStrings, key.String())
+	for _, element := range maplit.Elements {
+		valueStrings = append(valueStrings, element.Key.String())
 		valueStrings[len(valueStrings)-1] += ": "
-		valueStrings[len(valueStrings)-1] += value.String()
+		valueStrings[len(valueStrings)-1] += element.Value.String()
 	}
 
 	result += strings.Join(valueStrings, ", ")
@@ -135,11 +162,30 @@ func (maplit *MapLiteral) String() string {
 	return result
 }
 
+type NamedArgument struct {
+	Name  string
+	Value Expression
+}
+
+// Passes each element of a list as a separate argument
+type SpreadExpression struct {
+	BaseNode
+	BaseExpression
+	Value Expression
+}
+
+func (spread *SpreadExpression) Type() NodeType { return "SpreadExpression" }
+
+func (spread *SpreadExpression) String() string {
+	return "..." + spread.Value.String()
+}
+
 type FunctionCall struct {
 	BaseNode
 	BaseExpression
-	Left Expression
-	Args []Expression
+	Left      Expression
+	Args      []Expression
+	NamedArgs []NamedArgument
 }
 
 func (fn *FunctionCall) Type() NodeType { return "FunctionCall" }
@@ -149,12 +195,14 @@ func (fn *FunctionCall) String() string {
 
 	result += "("
 
-	for i, arg := range fn.Args {
-		result += arg.String()
-		if i != len(fn.Args)-1 {
-			result += ", "
-		}
+	args := []string{}
+	for _, arg := range fn.Args {
+		args = append(args, arg.String())
+	}
+	for _, arg := range fn.NamedArgs {
+		args = append(args, arg.Name+": "+arg.Value.String())
 	}
+	result += strings.Join(args, ", ")
 
 	result += ")"
 
@@ -167,6 +215,8 @@ type BinaryOperation struct {
 	Left     Expression
 	Operator string
 	Right    Expression
+	// The method of the left operand's type which implements the operator, if it is overloaded
+	Method string
 }
 
 func (binOp *BinaryOperation) Type() NodeType { return "BinaryOperation" }
@@ -208,6 +258,8 @@ type AssignmentExpression struct {
 	Assignee  Expression
 	Value     Expression
 	Operation string
+	// The method implementing the operator of a compound assignment, if it is overloaded
+	Method string
 }
 
 func (ae *AssignmentExpression) Type() NodeType { return "AssignmentExpression" }
@@ -229,33 +281,110 @@ type IndexExpression struct {
 	BaseExpression
 	Left  Expression
 	Index Expression
+	// What the brackets hold if read as a type, for passing type arguments to functions (f[T]).
+	// Index is nil if they can only be a type
+	TypeArgument TypeExpression
 }
 
 func (index *IndexExpression) Type() NodeType { return "IndexExpression" }
 
 func (index *IndexExpression) String() string {
+	if index.Index == nil {
+		return fmt.Sprintf("%s[%s]", index.Left.String(), index.TypeArgument.String())
+	}
 	return fmt.Sprintf("%s[%s]", index.Left.String(), index.Index.String())
 }
 
+// Takes part of a list, array or string. Either bound can be left off
+type SliceExpression struct {
+	BaseNode
+	BaseExpression
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (slice *SliceExpression) Type() NodeType { return "SliceExpression" }
+
+func (slice *SliceExpression) String() string {
+	start, end := "", ""
+	if slice.Start != nil {
+		start = slice.Start.String()
+	}
+	if slice.End != nil {
+		end = slice.End.String()
+	}
+	return fmt.Sprintf("%s[%s:%s]", slice.Left.String(), start, end)
+}
+
+// An if statement used as a value, giving the value of the branch that was taken
+type IfExpression struct {
+	BaseNode
+	BaseExpression
+	Statement *IfStatement
+}
+
+func (ifExpr *IfExpression) Type() NodeType { return "IfExpression" }
+
+func (ifExpr *IfExpression) String() string {
+	return ifExpr.Statement.String()
+}
+
+// A block of code, whose value is its final expression
+type BlockExpression struct {
+	BaseNode
+	BaseExpression
+	Body []Statement
+}
+
+func (block *BlockExpression) Type() NodeType { return "BlockExpression" }
+
+func (block *BlockExpression) String() string {
+	result := "{\n"
+
+	for _, statement := range block.Body {
+		result += "  "
+		result += statement.String()
+		result += "\n"
+	}
+
+	result += "}"
+
+	return result
+}
+
 type MemberExpression struct {
 	BaseNode
 	BaseExpression
 	Left           Expression
 	Member         string
 	IsNumberMember bool
+	// Set for safe navigation (a?.b), which gives null if the left side is null
+	Optional bool
+	// The type whose associated function is accessed, if the left side is a type
+	Associated types.ValidType
 }
 
 func (member *MemberExpression) Type() NodeType { return "MemberExpression" }
 
 func (member *MemberExpression) String() string {
+	if member.Optional {
+		return fmt.Sprintf("%s?.%s", member.Left.String(), member.Member)
+	}
 	return fmt.Sprintf("%s.%s", member.Left.String(), member.Member)
 }
 
+type StructMember struct {
+	Name  string
+	Value Expression
+}
+
 type StructExpression struct {
 	BaseNode
 	BaseExpression
 	InstanceOf Expression
-	Members    map[string]Expression
+	// In the order they were written
+	Members []StructMember
 }
 
 func (*StructExpression) Type() NodeType { return "StructExpression" }
@@ -264,10 +393,10 @@ func (structExpr *StructExpression) String() string {
 	result := structExpr.InstanceOf.String()
 	result += " {\n"
 
-	for memberName, memberValue := range structExpr.Members {
-		result += memberName
+	for _, member := range structExpr.Members {
+		result += member.Name
 		result += ": "
-		result += memberValue.String()
+		result += member.Value.String()
 		result += ",\n"
 	}
 
@@ -324,3 +453,32 @@ func (*CastExpression) Type() NodeType { return "CastExpression" }
 func (typeCast *CastExpression) String() string {
 	return typeCast.Left.String() + " -> " + typeCast.DataType.String()
 }
+
+type SpawnExpression struct {
+	BaseNode
+	BaseExpression
+	Call *FunctionCall
+}
+
+func (*SpawnExpression) Type() NodeType { return "SpawnExpression" }
+
+func (spawn *SpawnExpression) String() string {
+	return "spawn " + spawn.Call.String()
+}
+
+type ChannelExpression struct {
+	BaseNode
+	BaseExpression
+	ElemType TypeExpression
+	Capacity Expression
+}
+
+func (*ChannelExpression) Type() NodeType { return "ChannelExpression" }
+
+func (channel *ChannelExpression) String() string {
+	capacity := ""
+	if channel.Capacity != nil {
+		capacity = channel.Capacity.String()
+	}
+	return fmt.Sprintf("chan[%s](%s)", channel.ElemType.String(), capacity)
+}
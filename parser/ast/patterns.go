@@ -0,0 +1,80 @@
+package ast
+
+import "strings"
+
+type BasePattern struct{}
+
+func (pat *BasePattern) patternNode() {}
+
+// Binds the whole value to a variable. The name "_" ignores the value
+type IdentifierPattern struct {
+	BaseNode
+	BasePattern
+	Name string
+}
+
+func (*IdentifierPattern) Type() NodeType { return "IdentifierPattern" }
+
+func (ident *IdentifierPattern) String() string {
+	return ident.Name
+}
+
+// Destructures a tuple, or a tuple struct if Struct is set
+type TuplePattern struct {
+	BaseNode
+	BasePattern
+	Struct   string
+	Elements []Pattern
+}
+
+func (*TuplePattern) Type() NodeType { return "TuplePattern" }
+
+func (tuple *TuplePattern) String() string {
+	return tuple.Struct + "(" + joinPatterns(tuple.Elements) + ")"
+}
+
+type ListPattern struct {
+	BaseNode
+	BasePattern
+	Elements []Pattern
+}
+
+func (*ListPattern) Type() NodeType { return "ListPattern" }
+
+func (list *ListPattern) String() string {
+	return "[" + joinPatterns(list.Elements) + "]"
+}
+
+type FieldPattern struct {
+	Name    string
+	Pattern Pattern
+}
+
+type StructPattern struct {
+	BaseNode
+	BasePattern
+	Struct string
+	Fields []FieldPattern
+}
+
+func (*StructPattern) Type() NodeType { return "StructPattern" }
+
+func (structPat *StructPattern) String() string {
+	fields := []string{}
+	for _, field := range structPat.Fields {
+		if ident, ok := field.Pattern.(*IdentifierPattern); ok && ident.Name == field.Name {
+			fields = append(fields, field.Name)
+		} else {
+			fields = append(fields, field.Name+": "+field.Pattern.String())
+		}
+	}
+	return structPat.Struct + " { " + strings.Join(fields, ", ") + " }"
+}
+
+func joinPatterns(patterns []Pattern) string {
+	strs := []string{}
+	for _, pattern := range patterns {
+		strs = append(strs, pattern.String())
+	}
+	return strings.Join(strs, ", ")
+}
@@ -40,6 +40,8 @@ type VariableDeclaration struct {
 	BaseStatement
 	Constant bool
 	Name     string
+	// Destructures the value into several variables, instead of declaring Name
+	Pattern  Pattern
 	Value    Expression
 	DataType TypeExpression
 }
@@ -55,7 +57,11 @@ func (varDec *VariableDeclaration) String() string {
 		result += "var"
 	}
 	result += " "
-	result += varDec.Name
+	if varDec.Pattern != nil {
+		result += varDec.Pattern.String()
+	} else {
+		result += varDec.Name
+	}
 	result += " = "
 	result += varDec.Value.String()
 
@@ -65,17 +71,25 @@ func (varDec *VariableDeclaration) String() string {
 type Parameter struct {
 	Name string
 	Type TypeExpression
+	// The value used when no argument is passed, or nil if one is required
+	Default Expression
+	// Whether the parameter collects any remaining arguments into a list
+	Variadic bool
 }
 
 type FunctionDeclaration struct {
 	BaseNode
 	BaseStatement
 	canExport
-	Name       string
-	MethodOf   TypeExpression
-	Parameters []Parameter
-	ReturnType TypeExpression
-	Body       []Statement
+	Name     string
+	MethodOf TypeExpression
+	// Whether the function is called through its type (T.name), rather than on a value
+	Associated bool
+	// Whether `this` points to the value the method was called on, so it can be modified
+	PointerReceiver bool
+	Parameters      []Parameter
+	ReturnType      TypeExpression
+	Body            []Statement
 }
 
 func (funcDec *FunctionDeclaration) Type() NodeType { return "FunctionDeclaration" }
@@ -87,9 +101,16 @@ func (funcDec *FunctionDeclaration) String() string {
 	result += "("
 
 	for i, parameter := range funcDec.Parameters {
+		if parameter.Variadic {
+			result += "..."
+		}
 		result += parameter.Name
 		result += " "
 		result += parameter.Type.String()
+		if parameter.Default != nil {
+			result += " = "
+			result += parameter.Default.String()
+		}
 
 		if i != len(funcDec.Parameters)-1 {
 			result += ", "
@@ -121,6 +142,18 @@ func (ret *ReturnStatement) String() string {
 	return "return " + ret.Value.String()
 }
 
+type DeferStatement struct {
+	BaseNode
+	BaseStatement
+	Call *FunctionCall
+}
+
+func (deferStmt *DeferStatement) Type() NodeType { return "DeferStatement" }
+
+func (deferStmt *DeferStatement) String() string {
+	return "defer " + deferStmt.Call.String()
+}
+
 type IfElseStatement interface{ ifElse() }
 
 type IfStatement struct {
@@ -232,6 +265,7 @@ func (forLoop *ForLoop) String() string {
 }
 
 type StructField struct {
+	Name     string
 	Type     TypeExpression
 	Exported bool
 }
@@ -240,8 +274,9 @@ type StructDeclaration struct {
 	BaseNode
 	BaseStatement
 	canExport
-	Name    string
-	Members map[string]StructField
+	Name string
+	// In the order they were declared
+	Members []StructField
 }
 
 func (structDec *StructDeclaration) Type() NodeType { return "StructDeclaration" }
@@ -252,8 +287,8 @@ func (structDec *StructDeclaration) String() string {
 	result += structDec.Name
 	result += " {\n"
 
-	for name, field := range structDec.Members {
-		result += name
+	for _, field := range structDec.Members {
+		result += field.Name
 		result += " "
 		result += field.Type.String()
 		result += "\n"
@@ -308,8 +343,11 @@ func (structDec *UnitStructDeclaration) String() string {
 type InterfaceMember struct {
 	Name       string
 	IsFunction bool
-	Parameters []TypeExpression
+	// Parameters of a method. Their names are optional unless there is a default body
+	Parameters []Parameter
 	ResultType TypeExpression
+	// The default implementation of a method, used by types which don't define it
+	Body []Statement
 }
 
 type InterfaceDeclaration struct {
@@ -318,6 +356,8 @@ type InterfaceDeclaration struct {
 	canExport
 	Name    string
 	Members []InterfaceMember
+	// Other interfaces whose members are included in this one
+	Embeds []TypeExpression
 }
 
 func (intDecl *InterfaceDeclaration) Type() NodeType { return "InterfaceDeclaration" }
@@ -326,6 +366,21 @@ func (intDecl *InterfaceDeclaration) String() string {
 	return "interface {}"
 }
 
+type ImplDeclaration struct {
+	BaseNode
+	BaseStatement
+	Interface TypeExpression
+	For       TypeExpression
+	// The default methods the type takes from the interface, filled in by the type checker
+	Methods []*FunctionDeclaration
+}
+
+func (*ImplDeclaration) Type() NodeType { return "ImplDeclaration" }
+
+func (impl *ImplDeclaration) String() string {
+	return "impl " + impl.Interface.String() + " for " + impl.For.String()
+}
+
 type TypeDeclaration struct {
 	BaseNode
 	BaseStatement
@@ -369,17 +424,71 @@ type EnumDeclaration struct {
 	Exportable
 	IsUnion bool
 	Name    string
-	Members map[string]EnumMember
+	// In the order they were declared
+	Members []EnumMember
 }
 
 type EnumMember struct {
 	Name          string
 	Exported      bool
 	Types         []TypeExpression
-	StructMembers map[string]StructField
+	StructMembers []StructField
+	// The value given with `Name = value`, if any
+	Discriminant Expression
 }
 
 func (*EnumDeclaration) Type() NodeType { return "EnumDeclaration" }
 func (enum *EnumDeclaration) String() string {
 	return "enum " + enum.Name
 }
+
+type SelectCase struct {
+	// The name of the variable the received value is assigned to, if any
+	Variable string
+	// Either a receive (<-ch) or a send (ch <- value)
+	Operation Expression
+	Body      []Statement
+}
+
+type SelectStatement struct {
+	BaseNode
+	BaseStatement
+	Cases   []SelectCase
+	Default []Statement
+}
+
+func (*SelectStatement) Type() NodeType { return "SelectStatement" }
+
+func (selectStmt *SelectStatement) String() string {
+	result := "select {\n"
+
+	for _, selectCase := range selectStmt.Cases {
+		result += "  case "
+		if selectCase.Variable != "" {
+			result += "var " + selectCase.Variable + " = "
+		}
+		result += selectCase.Operation.String()
+		result += " {\n"
+
+		for _, statement := range selectCase.Body {
+			result += "    "
+			result += statement.String()
+			result += "\n"
+		}
+		result += "  }\n"
+	}
+
+	if selectStmt.Default != nil {
+		result += "  default {\n"
+		for _, statement := range selectStmt.Default {
+			result += "    "
+			result += statement.String()
+			result += "\n"
+		}
+		result += "  }\n"
+	}
+
+	result += "}"
+
+	return result
+}
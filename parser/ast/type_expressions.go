@@ -115,14 +115,26 @@ type ErrorType struct {
 	BaseNode
 	BaseType
 	ResultType TypeExpression
+	// The errors which can be returned, or nil if any error can be
+	ErrorTypes []TypeExpression
 }
 
 func (e *ErrorType) Type() NodeType { return "Error" }
 func (e *ErrorType) String() string {
+	result := e.ResultType.String() + "!"
 	if e.ResultType.Type() == "Union" {
-		return fmt.Sprintf("(%s)!", e.ResultType.String())
+		result = fmt.Sprintf("(%s)!", e.ResultType.String())
 	}
-	return e.ResultType.String() + "!"
+
+	for i, errorType := range e.ErrorTypes {
+		if i == 0 {
+			result = strings.TrimSuffix(result, "!") + " ! "
+		} else {
+			result += " | "
+		}
+		result += errorType.String()
+	}
+	return result
 }
 
 type TupleType struct {
@@ -163,12 +175,42 @@ type PointerType struct {
 	BaseNode
 	BaseType
 	DataType TypeExpression
+	Const    bool
 }
 
 func (*PointerType) Type() NodeType { return "Pointer" }
 func (p *PointerType) String() string {
+	result := p.DataType.String() + "*"
 	if p.DataType.Type() == "Union" {
-		return fmt.Sprintf("(%s)*", p.DataType.String())
+		result = fmt.Sprintf("(%s)*", p.DataType.String())
+	}
+	if p.Const {
+		return "const " + result
+	}
+	return result
+}
+
+type OptionalType struct {
+	BaseNode
+	BaseType
+	DataType TypeExpression
+}
+
+func (*OptionalType) Type() NodeType { return "Optional" }
+func (o *OptionalType) String() string {
+	if o.DataType.Type() == "Union" {
+		return fmt.Sprintf("(%s)?", o.DataType.String())
 	}
-	return p.DataType.String() + "*"
+	return o.DataType.String() + "?"
+}
+
+type ChannelType struct {
+	BaseNode
+	BaseType
+	ElemType TypeExpression
+}
+
+func (*ChannelType) Type() NodeType { return "Channel" }
+func (c *ChannelType) String() string {
+	return fmt.Sprintf("chan[%s]", c.ElemType.String())
 }
@@ -0,0 +1,40 @@
+package ast
+
+import "reflect"
+
+// Calls visit for every node in part of a tree, parents before their children.
+// If visit returns false, the children of that node are skipped
+func Inspect(node any, visit func(Node) bool) {
+	inspectValue(reflect.ValueOf(node), visit)
+}
+
+func inspectValue(value reflect.Value, visit func(Node) bool) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() || !value.CanInterface() || value.Type().Elem().PkgPath() != astPackage {
+			return
+		}
+		if node, ok := value.Interface().(Node); ok && !visit(node) {
+			return
+		}
+		inspectValue(value.Elem(), visit)
+
+	case reflect.Interface:
+		if !value.IsNil() {
+			inspectValue(value.Elem(), visit)
+		}
+
+	case reflect.Struct:
+		if value.Type().PkgPath() != astPackage {
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			inspectValue(value.Field(i), visit)
+		}
+
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			inspectValue(value.Index(i), visit)
+		}
+	}
+}
@@ -45,7 +45,8 @@ func (p *parser) parseBinaryOperation(minPrecedence int) (ast.Expression, error)
 		return nil, err
 	}
 
-	for p.canContinue() {
+	// Pipelines are often split across lines, with each stage starting a new one
+	for p.canContinue() || p.next().Type == token.PIPE_RIGHT_ANGLE {
 		opInfo, isOp := token.BinOpInfo[p.next().Type]
 		if !isOp || opInfo.Precedence < minPrecedence {
 			break
@@ -63,6 +64,14 @@ func (p *parser) parseBinaryOperation(minPrecedence int) (ast.Expression, error)
 			return nil, err
 		}
 
+		if op == "|>" {
+			left, err = p.pipe(left, right)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		left = &ast.BinaryOperation{
 			Left:     left,
 			Operator: op,
@@ -74,6 +83,21 @@ func (p *parser) parseBinaryOperation(minPrecedence int) (ast.Expression, error)
 	return left, nil
 }
 
+// Desugars `x |> f(y)` into `f(x, y)`, located at the stage so errors in the call point to it
+func (p *parser) pipe(value, stage ast.Expression) (ast.Expression, error) {
+	call, isCall := stage.(*ast.FunctionCall)
+	if !isCall {
+		return nil, p.error("Expected function call after |>", stage.GetToken())
+	}
+
+	return &ast.FunctionCall{
+		Left:      call.Left,
+		Args:      append([]ast.Expression{value}, call.Args...),
+		NamedArgs: call.NamedArgs,
+		BaseNode:  ast.BaseNode{Token: stage.GetToken()},
+	}, nil
+}
+
 func (p *parser) parseTypeCheckExpression() (ast.Expression, error) {
 	left, err := p.parsePrefixOperation()
 	if err != nil {
@@ -97,7 +121,30 @@ func (p *parser) parseTypeCheckExpression() (ast.Expression, error) {
 	return left, nil
 }
 
+func (p *parser) parseSpawnExpression() (ast.Expression, error) {
+	tok := p.consume()
+
+	value, err := p.parsePostfixOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	call, ok := value.(*ast.FunctionCall)
+	if !ok {
+		return nil, p.error("Expected function call after spawn", value.GetToken())
+	}
+
+	return &ast.SpawnExpression{
+		Call:     call,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
 func (p *parser) parsePrefixOperation() (ast.Expression, error) {
+	if p.isKeyword("spawn") {
+		return p.parseSpawnExpression()
+	}
+
 	if !p.next().Is(token.PrefixOperator) {
 		return p.parsePostfixOperation()
 	}
@@ -117,7 +164,7 @@ func (p *parser) parsePrefixOperation() (ast.Expression, error) {
 }
 
 func (p *parser) parsePostfixOperation() (ast.Expression, error) {
-	left, err := p.parseCastExpression()
+	left, err := p.parseLiteral()
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +174,10 @@ func (p *parser) parsePostfixOperation() (ast.Expression, error) {
 			left, err = p.parseFunctionCall(left)
 		} else if p.next().Type == token.LEFT_SQUARE {
 			left, err = p.parseIndexExpression(left)
-		} else if p.next().Type == token.DOT {
+		} else if p.next().Type == token.DOT || p.next().Type == token.QUESTION_DOT {
 			left, err = p.parseMemberExpression(left)
+		} else if p.next().Type == token.ARROW {
+			left, err = p.parseCastExpression(left)
 		} else if p.next().Type == token.LEFT_BRACE && !p.noBraces {
 			left, err = p.parseStructExpression(left)
 		} else if p.next().Is(token.PostfixOperator) {
@@ -151,7 +200,7 @@ func (p *parser) parsePostfixOperation() (ast.Expression, error) {
 }
 
 func (p *parser) parseMemberExpression(left ast.Expression) (ast.Expression, error) {
-	p.consume()
+	optional := p.consume().Type == token.QUESTION_DOT
 	isNumberMember := false
 	memberName := p.consume()
 	if memberName.Type == token.INTEGER {
@@ -163,7 +212,9 @@ func (p *parser) parseMemberExpression(left ast.Expression) (ast.Expression, err
 			Member:         strings.Split(memberName.Value, ".")[0],
 			BaseNode:       ast.BaseNode{Token: left.GetToken()},
 			IsNumberMember: true,
+			Optional:       optional,
 		}
+		optional = false
 		memberName.Value = strings.Split(memberName.Value, ".")[1]
 	} else if memberName.Type != token.IDENTIFIER {
 		return nil, p.error(fmt.Sprintf("Invalid member name %q", memberName.Value), memberName)
@@ -174,44 +225,105 @@ func (p *parser) parseMemberExpression(left ast.Expression) (ast.Expression, err
 		Member:         memberName.Value,
 		BaseNode:       ast.BaseNode{Token: left.GetToken()},
 		IsNumberMember: isNumberMember,
+		Optional:       optional,
 	}, nil
 }
 
 func (p *parser) parseIndexExpression(left ast.Expression) (ast.Expression, error) {
 	p.consume()
-	index, err := p.parseExpression()
+
+	typeArgument, afterType := p.parseTypeArgument()
+
+	var index ast.Expression
+	var err error
+	if p.next().Type != token.COLON {
+		index, err = p.parseExpression()
+		// Some types, such as int[] or int | string, aren't valid expressions, or only part of them is
+		endsIndex := err == nil && (p.next().Type == token.RIGHT_SQUARE || p.next().Type == token.COLON)
+		if !endsIndex {
+			if typeArgument == nil {
+				if err == nil {
+					_, err = p.expect(token.RIGHT_SQUARE, "Unexpected token %q, expecting ']'")
+				}
+				return nil, err
+			}
+			*p = afterType
+			index = nil
+		}
+	}
+
+	if p.next().Type == token.COLON {
+		return p.parseSliceExpression(left, index)
+	}
+
+	_, err = p.expect(token.RIGHT_SQUARE, "Unexpected token %q, expecting ']'")
 	if err != nil {
 		return nil, err
 	}
+
+	return &ast.IndexExpression{
+		Left:         left,
+		Index:        index,
+		TypeArgument: typeArgument,
+		BaseNode:     ast.BaseNode{Token: left.GetToken()},
+	}, nil
+}
+
+// Tries to read the contents of square brackets as a type, returning the parser's state after it if it works
+func (p *parser) parseTypeArgument() (ast.TypeExpression, parser) {
+	saved := *p
+	defer func() { *p = saved }()
+
+	dataType, err := p.parseType()
+	if err != nil || p.next().Type != token.RIGHT_SQUARE {
+		return nil, saved
+	}
+	return dataType, *p
+}
+
+func (p *parser) parseSliceExpression(left, start ast.Expression) (ast.Expression, error) {
+	p.consume()
+
+	var end ast.Expression
+	var err error
+	if p.next().Type != token.RIGHT_SQUARE {
+		end, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	_, err = p.expect(token.RIGHT_SQUARE, "Unexpected token %q, expecting ']'")
 	if err != nil {
 		return nil, err
 	}
 
-	return &ast.IndexExpression{
+	return &ast.SliceExpression{
 		Left:     left,
-		Index:    index,
+		Start:    start,
+		End:      end,
 		BaseNode: ast.BaseNode{Token: left.GetToken()},
 	}, nil
 }
 
 func (p *parser) parseFunctionCall(left ast.Expression) (ast.Expression, error) {
-	args, err := p.parseArgumentList()
+	args, namedArgs, err := p.parseArgumentList()
 	if err != nil {
 		return nil, err
 	}
 
 	return &ast.FunctionCall{
-		Left:     left,
-		Args:     args,
-		BaseNode: ast.BaseNode{Token: left.GetToken()},
+		Left:      left,
+		Args:      args,
+		NamedArgs: namedArgs,
+		BaseNode:  ast.BaseNode{Token: left.GetToken()},
 	}, nil
 }
 
 func (p *parser) parseStructExpression(left ast.Expression) (ast.Expression, error) {
 	p.consume()
 
-	members := map[string]ast.Expression{}
+	members := []ast.StructMember{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
 		memberName, err := p.expect(token.IDENTIFIER, "Invalid struct member name %q")
@@ -229,7 +341,7 @@ func (p *parser) parseStructExpression(left ast.Expression) (ast.Expression, err
 			return nil, err
 		}
 
-		members[memberName.Value] = memberValue
+		members = append(members, ast.StructMember{Name: memberName.Value, Value: memberValue})
 
 		if p.next().Type != token.RIGHT_BRACE {
 			_, err := p.expect(token.COMMA, "Expected comma or end of struct body")
@@ -262,6 +374,10 @@ func (p *parser) parseList() (ast.Expression, error) {
 		}
 		values = append(values, nextExpr)
 
+		if len(values) == 1 && p.isKeyword("for") {
+			return p.parseListComprehension(tok, nextExpr)
+		}
+
 		if p.next().Type != token.RIGHT_SQUARE {
 			_, err := p.expect(token.COMMA, "Expected comma or end of list")
 			if err != nil {
@@ -281,10 +397,51 @@ func (p *parser) parseList() (ast.Expression, error) {
 	}, nil
 }
 
+func (p *parser) parseListComprehension(tok token.Token, value ast.Expression) (ast.Expression, error) {
+	p.consume()
+
+	variable, err := p.expect(token.IDENTIFIER, "Expected variable name, got %q")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expectKeyword("in", "Expected \"in\", got %q")
+	if err != nil {
+		return nil, err
+	}
+
+	iterable, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	var condition ast.Expression
+	if p.isKeyword("if") {
+		p.consume()
+		condition, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.expect(token.RIGHT_SQUARE, "Unexpected %q, expecting ']'")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ListComprehension{
+		Value:     value,
+		Variable:  variable.Value,
+		Iterable:  iterable,
+		Condition: condition,
+		BaseNode:  ast.BaseNode{Token: tok},
+	}, nil
+}
+
 func (p *parser) parseMap() (ast.Expression, error) {
 	tok := p.consume()
 
-	values := map[ast.Expression]ast.Expression{}
+	values := []ast.MapElement{}
 
 	for p.next().Type != token.RIGHT_BRACE && !p.eof() {
 		keyExpr, err := p.parseExpression()
@@ -302,7 +459,7 @@ func (p *parser) parseMap() (ast.Expression, error) {
 			return nil, err
 		}
 
-		values[keyExpr] = valueExpr
+		values = append(values, ast.MapElement{Key: keyExpr, Value: valueExpr})
 
 		if p.next().Type != token.RIGHT_BRACE {
 			_, err = p.expect(token.COMMA, "Expected comma or end of map")
@@ -323,7 +480,102 @@ func (p *parser) parseMap() (ast.Expression, error) {
 	}, nil
 }
 
+// Braces start a map if they are empty or the first expression is followed by a colon, otherwise they are a block
+func (p *parser) isMap() bool {
+	saved := *p
+	defer func() { *p = saved }()
+
+	p.consume()
+	if p.next().Type == token.RIGHT_BRACE {
+		return true
+	}
+
+	_, err := p.parseExpression()
+	return err == nil && p.next().Type == token.COLON
+}
+
+func (p *parser) parseBlockExpression() (ast.Expression, error) {
+	tok := p.next()
+	bracketLevel, noBraces := p.bracketLevel, p.noBraces
+	p.bracketLevel, p.noBraces = 0, false
+
+	body, err := p.parseCodeBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	p.bracketLevel, p.noBraces = bracketLevel, noBraces
+	return &ast.BlockExpression{
+		Body:     body,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
+func (p *parser) parseIfExpression() (ast.Expression, error) {
+	tok := p.next()
+	bracketLevel := p.bracketLevel
+	p.bracketLevel = 0
+
+	ifStatement, err := p.parseIfStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	p.bracketLevel = bracketLevel
+	return &ast.IfExpression{
+		Statement: ifStatement,
+		BaseNode:  ast.BaseNode{Token: tok},
+	}, nil
+}
+
+func (p *parser) parseChannelExpression() (ast.Expression, error) {
+	tok := p.consume()
+	p.consume()
+
+	elemType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(token.RIGHT_SQUARE, "Unexpected %q, expecting ']'")
+	if err != nil {
+		return nil, err
+	}
+
+	args, namedArgs, err := p.parseArgumentList()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(namedArgs) != 0 {
+		return nil, p.error("Channels do not take named arguments", namedArgs[0].Value.GetToken())
+	}
+
+	if len(args) > 1 {
+		return nil, p.error("Channels take at most one argument, the capacity", args[1].GetToken())
+	}
+
+	var capacity ast.Expression = nil
+	if len(args) == 1 {
+		capacity = args[0]
+	}
+
+	return &ast.ChannelExpression{
+		ElemType: elemType,
+		Capacity: capacity,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
 func (p *parser) parseIdentifier() (ast.Expression, error) {
+	if p.isKeyword("if") {
+		return p.parseIfExpression()
+	}
+
+	if p.isKeyword("chan") && p.tokens[1].Type == token.LEFT_SQUARE {
+		return p.parseChannelExpression()
+	}
+
 	if p.isKeyword("true") {
 		tok := p.consume()
 		return &ast.BooleanLiteral{
@@ -354,27 +606,18 @@ func (p *parser) parseIdentifier() (ast.Expression, error) {
 	}, nil
 }
 
-func (p *parser) parseCastExpression() (ast.Expression, error) {
-	left, err := p.parseLiteral()
+func (p *parser) parseCastExpression(left ast.Expression) (ast.Expression, error) {
+	p.consume()
+	ty, err := p.parseType()
 	if err != nil {
 		return nil, err
 	}
 
-	for p.canContinue() && p.next().Type == token.ARROW {
-		p.consume()
-		ty, err := p.parseType()
-		if err != nil {
-			return nil, err
-		}
-
-		left = &ast.CastExpression{
-			BaseNode: ast.BaseNode{Token: left.GetToken()},
-			Left:     left,
-			DataType: ty,
-		}
-	}
-
-	return left, nil
+	return &ast.CastExpression{
+		BaseNode: ast.BaseNode{Token: left.GetToken()},
+		Left:     left,
+		DataType: ty,
+	}, nil
 }
 
 func (p *parser) parseLiteral() (ast.Expression, error) {
@@ -458,7 +701,10 @@ func (p *parser) parseLiteral() (ast.Expression, error) {
 		return p.parseList()
 
 	case token.LEFT_BRACE:
-		return p.parseMap()
+		if p.isMap() {
+			return p.parseMap()
+		}
+		return p.parseBlockExpression()
 
 	default:
 		return nil, p.error(fmt.Sprintf("Expected expression, got %q", p.next().Value), p.next())
@@ -1,52 +1,95 @@
 package parser
 
 import (
+	"fmt"
+
 	"github.com/gearsdatapacks/libra/lexer/token"
 	"github.com/gearsdatapacks/libra/parser/ast"
 )
 
-func (p *parser) parseArgumentList() ([]ast.Expression, error) {
+func (p *parser) parseArgumentList() ([]ast.Expression, []ast.NamedArgument, error) {
 	_, err := p.expect(token.LEFT_PAREN, "Expected '(' to open argument list, got %q")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	args := []ast.Expression{}
+	namedArgs := []ast.NamedArgument{}
 
 	if p.next().Type != token.RIGHT_PAREN {
-		args, err = p.parseArgs()
+		args, namedArgs, err = p.parseArgs()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	_, err = p.expect(token.RIGHT_PAREN, "Expected comma or end of argument list")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return args, nil
+	return args, namedArgs, nil
 }
 
-func (p *parser) parseArgs() ([]ast.Expression, error) {
+func (p *parser) parseArgs() ([]ast.Expression, []ast.NamedArgument, error) {
 	args := []ast.Expression{}
+	namedArgs := []ast.NamedArgument{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_PAREN {
-		nextExpr, err := p.parseExpression()
-		if err != nil {
-			return nil, err
+		if p.isNamedArgument() {
+			name := p.consume()
+			p.consume()
+
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, nil, err
+			}
+			namedArgs = append(namedArgs, ast.NamedArgument{Name: name.Value, Value: value})
+		} else {
+			if len(namedArgs) != 0 {
+				return nil, nil, p.error("Positional arguments cannot come after named arguments", p.next())
+			}
+
+			nextExpr, err := p.parseArgument()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, nextExpr)
 		}
-		args = append(args, nextExpr)
 
 		if p.next().Type != token.RIGHT_PAREN {
-			_, err = p.expect(token.COMMA, "Expected comma or end of argument list")
+			_, err := p.expect(token.COMMA, "Expected comma or end of argument list")
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 	}
 
-	return args, nil
+	return args, namedArgs, nil
+}
+
+func (p *parser) parseArgument() (ast.Expression, error) {
+	if p.next().Type != token.ELLIPSIS {
+		return p.parseExpression()
+	}
+
+	tok := p.consume()
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.SpreadExpression{
+		Value:    value,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
+// An argument of the form `name: value`
+func (p *parser) isNamedArgument() bool {
+	return len(p.tokens) > 1 &&
+		p.next().Type == token.IDENTIFIER &&
+		p.tokens[1].Type == token.COLON
 }
 
 func (p *parser) parseParameterList() ([]ast.Parameter, error) {
@@ -76,10 +119,17 @@ func (p *parser) parseParameters() ([]ast.Parameter, error) {
 	params := []ast.Parameter{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_PAREN {
+		paramToken := p.next()
 		nextParam, err := p.parseParameter()
 		if err != nil {
 			return nil, err
 		}
+		if len(params) != 0 && params[len(params)-1].Variadic {
+			return nil, p.error(fmt.Sprintf("Variadic parameter %q must be the last parameter", params[len(params)-1].Name), paramToken)
+		}
+		if nextParam.Default == nil && !nextParam.Variadic && len(params) != 0 && params[len(params)-1].Default != nil {
+			return nil, p.error(fmt.Sprintf("Parameter %q must have a default value, as it comes after one that does", nextParam.Name), paramToken)
+		}
 		params = append(params, nextParam)
 
 		if p.next().Type != token.RIGHT_PAREN {
@@ -94,6 +144,12 @@ func (p *parser) parseParameters() ([]ast.Parameter, error) {
 }
 
 func (p *parser) parseParameter() (ast.Parameter, error) {
+	variadic := false
+	if p.next().Type == token.ELLIPSIS {
+		p.consume()
+		variadic = true
+	}
+
 	name, err := p.expect(token.IDENTIFIER, "Invalid parameter name %q")
 	if err != nil {
 		return ast.Parameter{}, err
@@ -109,7 +165,24 @@ func (p *parser) parseParameter() (ast.Parameter, error) {
 		return ast.Parameter{}, err
 	}
 
-	return ast.Parameter{Name: name.Value, Type: dataType}, nil
+	var defaultValue ast.Expression
+	if p.next().Type == token.EQUALS {
+		if variadic {
+			return ast.Parameter{}, p.error(fmt.Sprintf("Variadic parameter %q cannot have a default value", name.Value), p.next())
+		}
+		p.consume()
+		defaultValue, err = p.parseExpression()
+		if err != nil {
+			return ast.Parameter{}, err
+		}
+	}
+
+	return ast.Parameter{
+		Name:     name.Value,
+		Type:     dataType,
+		Default:  defaultValue,
+		Variadic: variadic,
+	}, nil
 }
 
 func (p *parser) parseCodeBlock() ([]ast.Statement, error) {
@@ -120,6 +193,7 @@ func (p *parser) parseCodeBlock() ([]ast.Statement, error) {
 	if err != nil {
 		return nil, err
 	}
+	p.requireNewline = false
 
 	code := []ast.Statement{}
 
@@ -137,6 +211,7 @@ func (p *parser) parseCodeBlock() ([]ast.Statement, error) {
 	if err != nil {
 		return nil, err
 	}
+	p.requireNewline = false
 
 	return code, nil
 }
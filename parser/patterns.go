@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"github.com/gearsdatapacks/libra/lexer/token"
+	"github.com/gearsdatapacks/libra/parser/ast"
+)
+
+// Whether the next tokens start a destructuring pattern, rather than a single variable name
+func (p *parser) isDestructuring() bool {
+	switch p.next().Type {
+	case token.LEFT_PAREN, token.LEFT_SQUARE:
+		return true
+	case token.IDENTIFIER:
+		return len(p.tokens) > 1 &&
+			(p.tokens[1].Type == token.LEFT_BRACE || p.tokens[1].Type == token.LEFT_PAREN)
+	}
+	return false
+}
+
+func (p *parser) parsePattern() (ast.Pattern, error) {
+	switch p.next().Type {
+	case token.LEFT_PAREN:
+		tok := p.next()
+		elements, err := p.parsePatternList(token.RIGHT_PAREN)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.TuplePattern{Elements: elements, BaseNode: ast.BaseNode{Token: tok}}, nil
+
+	case token.LEFT_SQUARE:
+		tok := p.next()
+		elements, err := p.parsePatternList(token.RIGHT_SQUARE)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ListPattern{Elements: elements, BaseNode: ast.BaseNode{Token: tok}}, nil
+	}
+
+	name, err := p.expect(token.IDENTIFIER, "Invalid variable name %q")
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.next().Type {
+	case token.LEFT_PAREN:
+		elements, err := p.parsePatternList(token.RIGHT_PAREN)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.TuplePattern{Struct: name.Value, Elements: elements, BaseNode: ast.BaseNode{Token: name}}, nil
+
+	case token.LEFT_BRACE:
+		return p.parseStructPattern(name)
+	}
+
+	return &ast.IdentifierPattern{Name: name.Value, BaseNode: ast.BaseNode{Token: name}}, nil
+}
+
+func (p *parser) parsePatternList(close token.Type) ([]ast.Pattern, error) {
+	p.consume()
+
+	elements := []ast.Pattern{}
+	for !p.eof() && p.next().Type != close {
+		element, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+
+		if p.next().Type != close {
+			_, err = p.expect(token.COMMA, "Expected comma or end of pattern")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err := p.expect(close, "Expected comma or end of pattern")
+	if err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
+
+func (p *parser) parseStructPattern(name token.Token) (ast.Pattern, error) {
+	p.consume()
+
+	fields := []ast.FieldPattern{}
+	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
+		field, err := p.expect(token.IDENTIFIER, "Invalid field name %q")
+		if err != nil {
+			return nil, err
+		}
+
+		// `x` is short for `x: x`
+		var pattern ast.Pattern = &ast.IdentifierPattern{Name: field.Value, BaseNode: ast.BaseNode{Token: field}}
+		if p.next().Type == token.COLON {
+			p.consume()
+			pattern, err = p.parsePattern()
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, ast.FieldPattern{Name: field.Value, Pattern: pattern})
+
+		if p.next().Type != token.RIGHT_BRACE {
+			_, err = p.expect(token.COMMA, "Expected comma or end of pattern")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err := p.expect(token.RIGHT_BRACE, "Expected comma or end of pattern")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.StructPattern{Struct: name.Value, Fields: fields, BaseNode: ast.BaseNode{Token: name}}, nil
+}
+
+// The names of all the variables a pattern declares
+func patternNames(pattern ast.Pattern) []string {
+	names := []string{}
+
+	switch pat := pattern.(type) {
+	case *ast.IdentifierPattern:
+		names = append(names, pat.Name)
+	case *ast.TuplePattern:
+		for _, element := range pat.Elements {
+			names = append(names, patternNames(element)...)
+		}
+	case *ast.ListPattern:
+		for _, element := range pat.Elements {
+			names = append(names, patternNames(element)...)
+		}
+	case *ast.StructPattern:
+		for _, field := range pat.Fields {
+			names = append(names, patternNames(field.Pattern)...)
+		}
+	}
+
+	return names
+}
@@ -21,6 +21,8 @@ func (p *parser) parseStatement(inline ...bool) (ast.Statement, error) {
 		statement, err = p.parseFunctionDeclaration()
 	} else if p.isKeyword("return") {
 		statement, err = p.parseReturnStatement()
+	} else if p.isKeyword("defer") {
+		statement, err = p.parseDeferStatement()
 	} else if p.isKeyword("if") {
 		statement, err = p.parseIfStatement()
 	} else if p.isKeyword("else") {
@@ -33,6 +35,8 @@ func (p *parser) parseStatement(inline ...bool) (ast.Statement, error) {
 		statement, err = p.parseStructDeclaration()
 	} else if p.isKeyword("interface") {
 		statement, err = p.parseInterfaceDeclaration()
+	} else if p.isKeyword("impl") {
+		statement, err = p.parseImplDeclaration()
 	} else if p.isKeyword("type") {
 		statement, err = p.parseTypeDeclaration()
 	} else if p.isKeyword("import") {
@@ -41,6 +45,8 @@ func (p *parser) parseStatement(inline ...bool) (ast.Statement, error) {
 		statement, err = p.parseExportStatement()
 	} else if p.isKeyword("enum") || p.isKeyword("union") {
 		statement, err = p.parseEnumDeclaration()
+	} else if p.isKeyword("select") {
+		statement, err = p.parseSelectStatement()
 	} else {
 		statement, err = p.parseExpressionStatement()
 	}
@@ -72,6 +78,11 @@ func (p *parser) parseExpressionStatement() (ast.Statement, error) {
 func (p *parser) parseVariableDeclaration() (ast.Statement, error) {
 	tok := p.consume()
 	isConstant := tok.Value == "const"
+
+	if p.isDestructuring() {
+		return p.parseDestructuringDeclaration(tok, isConstant)
+	}
+
 	name, err := p.expect(
 		token.IDENTIFIER,
 		"Invalid variable name %q",
@@ -132,12 +143,63 @@ func (p *parser) parseVariableDeclaration() (ast.Statement, error) {
 	}, nil
 }
 
+func (p *parser) parseDestructuringDeclaration(tok token.Token, isConstant bool) (ast.Statement, error) {
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	var dataType ast.TypeExpression = &ast.InferType{}
+
+	if p.canContinue() && p.next().Type == token.COLON {
+		p.consume()
+		dataType, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.expect(token.EQUALS, "Cannot leave destructured variables uninitialised")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.usedSymbols = append(p.usedSymbols, patternNames(pattern)...)
+
+	return &ast.VariableDeclaration{
+		Constant: isConstant,
+		Pattern:  pattern,
+		BaseNode: ast.BaseNode{Token: tok},
+		Value:    value,
+		DataType: dataType,
+	}, nil
+}
+
 func (p *parser) parseFunctionDeclaration() (ast.Statement, error) {
 	tok := p.consume()
 
 	var methodOf ast.TypeExpression = nil
-	if p.next().Type == token.LEFT_PAREN {
+	associated := false
+	pointerReceiver := false
+	if p.next().Type == token.IDENTIFIER && len(p.tokens) > 1 && p.tokens[1].Type == token.DOT {
+		typeName := p.consume()
 		p.consume()
+		methodOf = &ast.TypeName{
+			BaseNode: ast.BaseNode{Token: typeName},
+			Name:     typeName.Value,
+		}
+		associated = true
+	} else if p.next().Type == token.LEFT_PAREN {
+		p.consume()
+		if p.next().Type == token.AMPERSAND {
+			p.consume()
+			pointerReceiver = true
+		}
 		var err error
 		methodOf, err = p.parseType()
 		if err != nil {
@@ -154,7 +216,9 @@ func (p *parser) parseFunctionDeclaration() (ast.Statement, error) {
 		return nil, err
 	}
 
-	p.usedSymbols = append(p.usedSymbols, name.Value)
+	if !associated {
+		p.usedSymbols = append(p.usedSymbols, name.Value)
+	}
 
 	parameters, err := p.parseParameterList()
 	if err != nil {
@@ -190,12 +254,14 @@ func (p *parser) parseFunctionDeclaration() (ast.Statement, error) {
 	p.usedSymbols = outerSymbols
 
 	return &ast.FunctionDeclaration{
-		Name:       name.Value,
-		Parameters: parameters,
-		Body:       code,
-		ReturnType: returnType,
-		BaseNode:   ast.BaseNode{Token: tok},
-		MethodOf:   methodOf,
+		Name:            name.Value,
+		Parameters:      parameters,
+		Body:            code,
+		ReturnType:      returnType,
+		BaseNode:        ast.BaseNode{Token: tok},
+		MethodOf:        methodOf,
+		Associated:      associated,
+		PointerReceiver: pointerReceiver,
 	}, nil
 }
 
@@ -218,6 +284,25 @@ func (p *parser) parseReturnStatement() (ast.Statement, error) {
 	}, nil
 }
 
+func (p *parser) parseDeferStatement() (ast.Statement, error) {
+	tok := p.consume()
+
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	call, ok := value.(*ast.FunctionCall)
+	if !ok {
+		return nil, p.error("Expected function call after defer", value.GetToken())
+	}
+
+	return &ast.DeferStatement{
+		Call:     call,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
 func (p *parser) parseIfStatement() (*ast.IfStatement, error) {
 	tok := p.consume()
 	noBraces := p.noBraces
@@ -266,11 +351,15 @@ func (p *parser) parseIfStatement() (*ast.IfStatement, error) {
 
 func (p *parser) parseWhileLoop() (ast.Statement, error) {
 	tok := p.consume()
+	noBraces := p.noBraces
+	p.noBraces = true
 
 	condition, err := p.parseExpression()
 	if err != nil {
 		return nil, err
 	}
+
+	p.noBraces = noBraces
 	body, err := p.parseCodeBlock()
 	if err != nil {
 		return nil, err
@@ -289,6 +378,9 @@ func (p *parser) parseForLoop() (ast.Statement, error) {
 	outerSymbols := make([]string, len(p.usedSymbols))
 	copy(outerSymbols, p.usedSymbols)
 
+	noBraces := p.noBraces
+	p.noBraces = true
+
 	initial, err := p.parseStatement(true)
 	if err != nil {
 		return nil, err
@@ -318,6 +410,8 @@ func (p *parser) parseForLoop() (ast.Statement, error) {
 		return nil, err
 	}
 
+	p.noBraces = noBraces
+
 	body, err := p.parseCodeBlock()
 	if err != nil {
 		return nil, err
@@ -358,14 +452,14 @@ func (p *parser) parseStructDeclaration() (ast.Statement, error) {
 		return nil, err
 	}
 
-	members := map[string]ast.StructField{}
+	members := []ast.StructField{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
-		name, field, err := p.parseStructField()
+		field, err := p.parseStructField()
 		if err != nil {
 			return nil, err
 		}
-		members[name] = field
+		members = append(members, field)
 
 		if p.next().Type != token.RIGHT_BRACE {
 			_, err = p.expect(token.COMMA, "Expected comma or end of struct body")
@@ -387,7 +481,7 @@ func (p *parser) parseStructDeclaration() (ast.Statement, error) {
 	}, nil
 }
 
-func (p *parser) parseStructField() (string, ast.StructField, error) {
+func (p *parser) parseStructField() (ast.StructField, error) {
 	exported := false
 	if p.isKeyword("pub") {
 		p.consume()
@@ -396,17 +490,17 @@ func (p *parser) parseStructField() (string, ast.StructField, error) {
 
 	memberName, err := p.expect(token.IDENTIFIER, "Expected closing brace or struct member")
 	if err != nil {
-		return "", ast.StructField{}, err
+		return ast.StructField{}, err
 	}
 	_, err = p.expect(token.COLON, "Expected type annotation")
 	if err != nil {
-		return "", ast.StructField{}, err
+		return ast.StructField{}, err
 	}
 	memberType, err := p.parseType()
 	if err != nil {
-		return "", ast.StructField{}, err
+		return ast.StructField{}, err
 	}
-	return memberName.Value, ast.StructField{Exported: exported, Type: memberType}, nil
+	return ast.StructField{Name: memberName.Value, Exported: exported, Type: memberType}, nil
 }
 
 func (p *parser) parseTupleStructDeclaration(tok token.Token, name string) (ast.Statement, error) {
@@ -450,6 +544,7 @@ func (p *parser) parseInterfaceDeclaration() (ast.Statement, error) {
 	}
 
 	members := []ast.InterfaceMember{}
+	embeds := []ast.TypeExpression{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
 		memberName, err := p.expect(token.IDENTIFIER, "Expected closing brace or interface member")
@@ -457,22 +552,34 @@ func (p *parser) parseInterfaceDeclaration() (ast.Statement, error) {
 			return nil, err
 		}
 
+		// A lone name embeds another interface
+		if p.next().Type == token.COMMA || p.next().Type == token.RIGHT_BRACE {
+			embeds = append(embeds, &ast.TypeName{Name: memberName.Value, BaseNode: ast.BaseNode{Token: memberName}})
+			if p.next().Type == token.COMMA {
+				p.consume()
+			}
+			continue
+		}
+
 		currentMember := ast.InterfaceMember{Name: memberName.Value}
 
 		if p.next().Type == token.LEFT_PAREN {
 			p.consume()
 			currentMember.IsFunction = true
-			currentMember.Parameters = []ast.TypeExpression{}
+			currentMember.Parameters = []ast.Parameter{}
 
 			for p.next().Type != token.RIGHT_PAREN {
-				nextType, err := p.parseType()
+				param, err := p.parseInterfaceParameter()
 				if err != nil {
 					return nil, err
 				}
-				currentMember.Parameters = append(currentMember.Parameters, nextType)
+				currentMember.Parameters = append(currentMember.Parameters, param)
 
 				if p.next().Type != token.RIGHT_PAREN {
-					return nil, p.error("Expected comma or end of parameter list", p.next())
+					_, err = p.expect(token.COMMA, "Expected comma or end of parameter list")
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 
@@ -489,8 +596,27 @@ func (p *parser) parseInterfaceDeclaration() (ast.Statement, error) {
 		}
 		currentMember.ResultType = resultType
 
+		hasBody := currentMember.IsFunction && p.next().Type == token.LEFT_BRACE
+		if hasBody {
+			for _, param := range currentMember.Parameters {
+				if param.Name == "" {
+					return nil, p.error(fmt.Sprintf("Parameters of default method %q must be named", currentMember.Name), memberName)
+				}
+			}
+
+			currentMember.Body, err = p.parseCodeBlock()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		members = append(members, currentMember)
 
+		// A method body already marks the end of the member, so the comma is optional
+		if hasBody && p.next().Type != token.COMMA {
+			continue
+		}
+
 		if p.next().Type != token.RIGHT_BRACE {
 			_, err = p.expect(token.COMMA, "Expected comma or end of interface body")
 			if err != nil {
@@ -508,6 +634,53 @@ func (p *parser) parseInterfaceDeclaration() (ast.Statement, error) {
 		BaseNode: ast.BaseNode{Token: tok},
 		Name:     name.Value,
 		Members:  members,
+		Embeds:   embeds,
+	}, nil
+}
+
+// Interface method parameters can either be named, or just a type
+func (p *parser) parseInterfaceParameter() (ast.Parameter, error) {
+	if !p.isNamedArgument() && p.next().Type != token.ELLIPSIS {
+		dataType, err := p.parseType()
+		if err != nil {
+			return ast.Parameter{}, err
+		}
+		return ast.Parameter{Type: dataType}, nil
+	}
+
+	paramToken := p.next()
+	param, err := p.parseParameter()
+	if err != nil {
+		return ast.Parameter{}, err
+	}
+	if param.Default != nil {
+		return ast.Parameter{}, p.error("Interface method parameters cannot have default values", paramToken)
+	}
+	return param, nil
+}
+
+func (p *parser) parseImplDeclaration() (ast.Statement, error) {
+	tok := p.consume()
+
+	interfaceType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expectKeyword("for", "Expected \"for\" after interface name, got %q")
+	if err != nil {
+		return nil, err
+	}
+
+	forType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ImplDeclaration{
+		BaseNode:  ast.BaseNode{Token: tok},
+		Interface: interfaceType,
+		For:       forType,
 	}, nil
 }
 
@@ -639,14 +812,14 @@ func (p *parser) parseEnumDeclaration() (ast.Statement, error) {
 		return nil, err
 	}
 
-	members := map[string]ast.EnumMember{}
+	members := []ast.EnumMember{}
 
 	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
 		member, err := p.parseEnumMember(tok.Value)
 		if err != nil {
 			return nil, err
 		}
-		members[member.Name] = member
+		members = append(members, member)
 
 		if p.next().Type != token.RIGHT_BRACE {
 			_, err = p.expect(token.COMMA, "Expected comma or end of "+tok.Value+" body")
@@ -682,7 +855,7 @@ func (p *parser) parseEnumMember(kind string) (ast.EnumMember, error) {
 	}
 
 	var types []ast.TypeExpression
-	var structMembers map[string]ast.StructField
+	var structMembers []ast.StructField
 	if p.next().Type == token.LEFT_PAREN {
 		p.consume()
 		types = []ast.TypeExpression{}
@@ -707,14 +880,14 @@ func (p *parser) parseEnumMember(kind string) (ast.EnumMember, error) {
 		}
 	} else if p.next().Type == token.LEFT_BRACE {
 		p.consume()
-		structMembers = map[string]ast.StructField{}
+		structMembers = []ast.StructField{}
 
 		for !p.eof() && p.next().Type != token.RIGHT_BRACE {
-			name, member, err := p.parseStructField()
+			member, err := p.parseStructField()
 			if err != nil {
 				return ast.EnumMember{}, err
 			}
-			structMembers[name] = member
+			structMembers = append(structMembers, member)
 
 			if p.next().Type != token.RIGHT_BRACE {
 				_, err := p.expect(token.COMMA, "Expected comma or end of struct")
@@ -730,10 +903,126 @@ func (p *parser) parseEnumMember(kind string) (ast.EnumMember, error) {
 		}
 	}
 
+	var discriminant ast.Expression
+	if p.next().Type == token.EQUALS {
+		if kind == "union" || types != nil || structMembers != nil {
+			return ast.EnumMember{}, p.error("Only enum members without data can have discriminants", p.next())
+		}
+		p.consume()
+
+		discriminant, err = p.parseExpression()
+		if err != nil {
+			return ast.EnumMember{}, err
+		}
+	}
+
 	return ast.EnumMember{
 		Name:          name.Value,
 		Exported:      exported,
 		Types:         types,
 		StructMembers: structMembers,
+		Discriminant:  discriminant,
 	}, nil
 }
+
+func (p *parser) parseSelectStatement() (ast.Statement, error) {
+	tok := p.consume()
+
+	_, err := p.expect(token.LEFT_BRACE, "Unexpected %q, expected '{'")
+	if err != nil {
+		return nil, err
+	}
+
+	selectStmt := &ast.SelectStatement{
+		BaseNode: ast.BaseNode{Token: tok},
+	}
+
+	for !p.eof() && p.next().Type != token.RIGHT_BRACE {
+		if p.isKeyword("default") {
+			defaultTok := p.consume()
+			if selectStmt.Default != nil {
+				return nil, p.error("Select statement cannot have more than one default case", defaultTok)
+			}
+
+			selectStmt.Default, err = p.parseCodeBlock()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		_, err := p.expectKeyword("case", "Expected case or default in select statement, got %q")
+		if err != nil {
+			return nil, err
+		}
+
+		selectCase, err := p.parseSelectCase()
+		if err != nil {
+			return nil, err
+		}
+		selectStmt.Cases = append(selectStmt.Cases, selectCase)
+	}
+
+	_, err = p.expect(token.RIGHT_BRACE, "Unexpected %q, expected '}'")
+	if err != nil {
+		return nil, err
+	}
+
+	return selectStmt, nil
+}
+
+func (p *parser) parseSelectCase() (ast.SelectCase, error) {
+	selectCase := ast.SelectCase{}
+
+	if p.isKeyword("var") {
+		p.consume()
+		name, err := p.expect(token.IDENTIFIER, "Invalid variable name %q")
+		if err != nil {
+			return selectCase, err
+		}
+
+		_, err = p.expect(token.EQUALS, "Unexpected %q, expected '='")
+		if err != nil {
+			return selectCase, err
+		}
+		selectCase.Variable = name.Value
+	}
+
+	noBraces := p.noBraces
+	p.noBraces = true
+
+	operation, err := p.parseExpression()
+	if err != nil {
+		return selectCase, err
+	}
+
+	p.noBraces = noBraces
+
+	unOp, isUnary := operation.(*ast.UnaryOperation)
+	isReceive := isUnary && unOp.Operator == "<-" && !unOp.Postfix
+	binOp, isBinary := operation.(*ast.BinaryOperation)
+	isSend := isBinary && binOp.Operator == "<-"
+
+	if !isReceive && !isSend {
+		return selectCase, p.error("Select cases must send to or receive from a channel", operation.GetToken())
+	}
+	if isSend && selectCase.Variable != "" {
+		return selectCase, p.error("Cannot assign the result of a send to a variable", operation.GetToken())
+	}
+	selectCase.Operation = operation
+
+	outerSymbols := make([]string, len(p.usedSymbols))
+	copy(outerSymbols, p.usedSymbols)
+	if selectCase.Variable != "" {
+		p.usedSymbols = append(p.usedSymbols, selectCase.Variable)
+	}
+
+	selectCase.Body, err = p.parseCodeBlock()
+	if err != nil {
+		return selectCase, err
+	}
+
+	p.usedSymbols = outerSymbols
+
+	return selectCase, nil
+}
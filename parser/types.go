@@ -41,6 +41,21 @@ func (p *parser) parseUnion() (ast.TypeExpression, error) {
 }
 
 func (p *parser) parseSuffixType() (ast.TypeExpression, error) {
+	// `const T*` is a pointer which can't be written through, so it can point to constants
+	if p.isKeyword("const") {
+		tok := p.consume()
+		dataType, err := p.parseSuffixType()
+		if err != nil {
+			return nil, err
+		}
+		pointer, isPointer := dataType.(*ast.PointerType)
+		if !isPointer {
+			return nil, p.error(fmt.Sprintf("Only pointer types can be constant, not %q", dataType), tok)
+		}
+		pointer.Const = true
+		return pointer, nil
+	}
+
 	leftType, err := p.parsePrimaryType()
 	if err != nil {
 		return nil, err
@@ -56,9 +71,16 @@ func (p *parser) parseSuffixType() (ast.TypeExpression, error) {
 
 		case token.BANG:
 			p.consume()
-			leftType = &ast.ErrorType{
-				ResultType: leftType,
-				BaseNode:   ast.BaseNode{Token: leftType.GetToken()},
+			leftType, err = p.parseErrorType(leftType, leftType.GetToken())
+			if err != nil {
+				return nil, err
+			}
+
+		case token.QUESTION:
+			p.consume()
+			leftType = &ast.OptionalType{
+				BaseNode: ast.BaseNode{Token: leftType.GetToken()},
+				DataType: leftType,
 			}
 
 		case token.STAR:
@@ -80,6 +102,31 @@ func (p *parser) parseSuffixType() (ast.TypeExpression, error) {
 	}
 }
 
+// Parses the errors that can be returned after the '!' of an error type, e.g. `Config ! IoError | ParseError`
+func (p *parser) parseErrorType(resultType ast.TypeExpression, tok token.Token) (ast.TypeExpression, error) {
+	errorType := &ast.ErrorType{
+		ResultType: resultType,
+		BaseNode:   ast.BaseNode{Token: tok},
+	}
+
+	if p.next().LeadingNewline || (p.next().Type != token.IDENTIFIER && p.next().Type != token.LEFT_PAREN) {
+		return errorType, nil
+	}
+
+	for {
+		nextType, err := p.parseSuffixType()
+		if err != nil {
+			return nil, err
+		}
+		errorType.ErrorTypes = append(errorType.ErrorTypes, nextType)
+
+		if p.next().Type != token.PIPE {
+			return errorType, nil
+		}
+		p.consume()
+	}
+}
+
 func (p *parser) parseArrayType(elemType ast.TypeExpression, tok token.Token) (ast.TypeExpression, error) {
 	if p.next().Type != token.LEFT_SQUARE {
 		var err error = nil
@@ -187,9 +234,33 @@ func (p *parser) parseMapType() (ast.TypeExpression, error) {
 	}, nil
 }
 
+func (p *parser) parseChannelType() (ast.TypeExpression, error) {
+	tok := p.consume()
+	p.consume()
+
+	elemType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(token.RIGHT_SQUARE, "Unexpected %q, expecting ']'")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ChannelType{
+		ElemType: elemType,
+		BaseNode: ast.BaseNode{Token: tok},
+	}, nil
+}
+
 func (p *parser) parsePrimaryType() (ast.TypeExpression, error) {
 	switch p.next().Type {
 	case token.IDENTIFIER:
+		if p.isKeyword("chan") && p.tokens[1].Type == token.LEFT_SQUARE {
+			return p.parseChannelType()
+		}
+
 		tok := p.consume()
 		return &ast.TypeName{Name: tok.Value, BaseNode: ast.BaseNode{Token: tok}}, nil
 
@@ -232,10 +303,7 @@ func (p *parser) parsePrimaryType() (ast.TypeExpression, error) {
 		return p.parseArrayType(elemType, tok)
 
 	case token.BANG:
-		return &ast.ErrorType{
-			BaseNode:   ast.BaseNode{Token: p.consume()},
-			ResultType: &ast.VoidType{},
-		}, nil
+		return p.parseErrorType(&ast.VoidType{}, p.consume())
 
 	default:
 		return nil, p.error(fmt.Sprintf("Expected type, got %q", p.next().Value), p.next())
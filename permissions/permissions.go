@@ -0,0 +1,122 @@
+package permissions
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Paths grants access to a set of directories and everything inside them,
+// or to the whole file system if All is set
+type Paths struct {
+	All  bool
+	Dirs []string
+}
+
+// Resolves a path to an absolute path with symlinks followed,
+// so that a link cannot be used to escape an allowed directory
+func resolve(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+
+	// The file might not exist yet, for example when writing to it
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
+
+func Contains(dir, path string) bool {
+	rel, err := filepath.Rel(resolve(dir), resolve(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (p Paths) Allows(path string) bool {
+	if p.All {
+		return true
+	}
+
+	for _, dir := range p.Dirs {
+		if Contains(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paths can be used as a command line flag. On its own it grants access to everything,
+// or it can be given a comma separated list of directories, e.g. --allow-read=src,data
+func (p *Paths) String() string {
+	if p.All {
+		return "true"
+	}
+	return strings.Join(p.Dirs, ",")
+}
+
+func (p *Paths) Set(value string) error {
+	switch value {
+	case "true":
+		p.All = true
+	case "false":
+		*p = Paths{}
+	default:
+		p.Dirs = append(p.Dirs, strings.Split(value, ",")...)
+	}
+	return nil
+}
+
+func (p *Paths) IsBoolFlag() bool {
+	return true
+}
+
+// Policy describes which parts of the outside world a program can access
+type Policy struct {
+	Read   Paths
+	Write  Paths
+	Env    bool
+	Run    bool
+	Prompt bool
+}
+
+// Programs can read from standard input, but can do nothing else outside of the interpreter unless they are granted permission
+func Default() Policy {
+	return Policy{Prompt: true}
+}
+
+func AllowAll() Policy {
+	return Policy{
+		Read:   Paths{All: true},
+		Write:  Paths{All: true},
+		Env:    true,
+		Run:    true,
+		Prompt: true,
+	}
+}
+
+func (p Policy) CanRead(path string) bool {
+	return p.Read.Allows(path)
+}
+
+func (p Policy) CanWrite(path string) bool {
+	return p.Write.Allows(path)
+}
+
+func (p Policy) CanAccessEnv() bool {
+	return p.Env
+}
+
+func (p Policy) CanRun() bool {
+	return p.Run
+}
+
+func (p Policy) CanPrompt() bool {
+	return p.Prompt
+}
@@ -0,0 +1,48 @@
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultOnlyAllowsPrompt(t *testing.T) {
+	policy := Default()
+	if !policy.CanPrompt() {
+		t.Error("expected reading from standard input to be allowed by default")
+	}
+	if policy.CanRead(".") || policy.CanWrite(".") || policy.CanAccessEnv() || policy.CanRun() {
+		t.Error("expected everything else to be denied by default")
+	}
+}
+
+func TestPathsOnlyAllowInsideDirectories(t *testing.T) {
+	dir := t.TempDir()
+	paths := Paths{Dirs: []string{filepath.Join(dir, "data")}}
+
+	if !paths.Allows(filepath.Join(dir, "data", "file.txt")) {
+		t.Error("expected a file inside an allowed directory to be allowed")
+	}
+	for _, path := range []string{
+		filepath.Join(dir, "database"),
+		filepath.Join(dir, "data", "..", "secret.txt"),
+	} {
+		if paths.Allows(path) {
+			t.Errorf("expected %q to be denied", path)
+		}
+	}
+}
+
+func TestSymlinkCannotEscapeAllowedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "data"), 0777)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0666)
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(dir, "data", "link")); err != nil {
+		t.Skip(err)
+	}
+
+	paths := Paths{Dirs: []string{filepath.Join(dir, "data")}}
+	if paths.Allows(filepath.Join(dir, "data", "link")) {
+		t.Error("expected a link to a file outside an allowed directory to be denied")
+	}
+}
@@ -6,6 +6,7 @@ import (
 )
 
 func register() {
-	interpreter.Register()
+	// The interpreter uses some of the types registered for the type checker
 	registry.Register()
+	interpreter.Register()
 }
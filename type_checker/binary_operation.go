@@ -15,7 +15,7 @@ func typeCheckBinaryOperation(binOp *ast.BinaryOperation, manager *modules.Modul
 		return leftType
 	}
 
-	rightType := typeCheckExpression(binOp.Right, manager)
+	rightType := typeCheckRightOperand(binOp, manager)
 	if rightType.String() == "TypeError" {
 		return rightType
 	}
@@ -28,7 +28,20 @@ func typeCheckBinaryOperation(binOp *ast.BinaryOperation, manager *modules.Modul
 
 	resultType := checkerFn(leftType, rightType)
 
+	// Equality is defined for every type, so a user defined eq method takes priority over it
+	if method, ok := registry.OperatorMethods[binOp.Operator]; ok && (resultType == nil || method == "eq") {
+		if types.Method(leftType, method, manager.Id) != nil {
+			return typeCheckOperatorMethod(binOp, method, leftType, rightType, manager)
+		}
+	}
+
 	if resultType == nil {
+		if isOptional(leftType) {
+			return optionalError(leftType, fmt.Sprintf("using operator %q", binOp.Operator), binOp.Left)
+		}
+		if isOptional(rightType) {
+			return optionalError(rightType, fmt.Sprintf("using operator %q", binOp.Operator), binOp.Right)
+		}
 		return types.Error(fmt.Sprintf("Operator %q is not defined for types %q and %q", binOp.Operator, leftType, rightType), binOp)
 	}
 
@@ -39,3 +52,50 @@ func typeCheckBinaryOperation(binOp *ast.BinaryOperation, manager *modules.Modul
 
 	return resultType
 }
+
+// Checks an operator overloaded by a method on the left operand's type
+func typeCheckOperatorMethod(binOp *ast.BinaryOperation, method string, leftType, rightType types.ValidType, manager *modules.ModuleManager) types.ValidType {
+	fn := types.Method(leftType, method, manager.Id)
+	if len(fn.Parameters) != 1 {
+		return types.Error(fmt.Sprintf("Method %q must take exactly one parameter to be used for operator %q", method, binOp.Operator), binOp)
+	}
+
+	if !fn.Parameters[0].Valid(rightType) {
+		return types.Error(fmt.Sprintf("Operator %q is not defined for types %q and %q", binOp.Operator, leftType, rightType), binOp)
+	}
+
+	resultType := fn.ReturnType
+	switch method {
+	case "eq":
+		if !(&types.BoolLiteral{}).Valid(fn.ReturnType) {
+			return types.Error(fmt.Sprintf("Method \"eq\" must return boolean to be used for operator %q", binOp.Operator), binOp)
+		}
+		resultType = &types.BoolLiteral{}
+	case "cmp":
+		if !(&types.IntLiteral{}).Valid(fn.ReturnType) {
+			return types.Error(fmt.Sprintf("Method \"cmp\" must return int to be used for operator %q", binOp.Operator), binOp)
+		}
+		resultType = &types.BoolLiteral{}
+	}
+
+	binOp.Method = method
+	return resultType
+}
+
+// The right side of a logical operator is only evaluated depending on the left,
+// so it can make use of what the left side tells us about types
+func typeCheckRightOperand(binOp *ast.BinaryOperation, manager *modules.ModuleManager) types.ValidType {
+	whenTrue, whenFalse := narrowCondition(binOp.Left, manager)
+
+	switch binOp.Operator {
+	case "&&":
+		enterNarrowedScope(whenTrue, manager)
+	case "||":
+		enterNarrowedScope(whenFalse, manager)
+	default:
+		return typeCheckExpression(binOp.Right, manager)
+	}
+
+	defer manager.ExitScope()
+	return typeCheckExpression(binOp.Right, manager)
+}
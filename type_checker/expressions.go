@@ -8,9 +8,14 @@ import (
 	"github.com/gearsdatapacks/libra/modules"
 	"github.com/gearsdatapacks/libra/parser/ast"
 	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
 	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
+func TypeCheckExpression(expr ast.Expression, manager *modules.ModuleManager) types.ValidType {
+	return typeCheckExpression(expr, manager)
+}
+
 func typeCheckExpression(expr ast.Expression, manager *modules.ModuleManager) types.ValidType {
 	dataType := doTypeCheckExpression(expr, manager)
 	if dataType.String() == "TypeError" {
@@ -75,6 +80,18 @@ func doTypeCheckExpression(expr ast.Expression, manager *modules.ModuleManager)
 	case *ast.MapLiteral:
 		dataType = typeCheckMap(expression, manager)
 
+	case *ast.SliceExpression:
+		dataType = typeCheckSliceExpression(expression, manager)
+
+	case *ast.ListComprehension:
+		dataType = typeCheckListComprehension(expression, manager)
+
+	case *ast.IfExpression:
+		dataType = typeCheckIfExpression(expression, manager)
+
+	case *ast.BlockExpression:
+		dataType = typeCheckBlockExpression(expression, manager)
+
 	case *ast.IndexExpression:
 		dataType = typeCheckIndexExpression(expression, manager)
 
@@ -93,6 +110,15 @@ func doTypeCheckExpression(expr ast.Expression, manager *modules.ModuleManager)
 	case *ast.TypeCheckExpression:
 		dataType = typeCheckTypeCheckExpression(expression, manager)
 
+	case *ast.SpawnExpression:
+		dataType = typeCheckSpawnExpression(expression, manager)
+
+	case *ast.ChannelExpression:
+		dataType = typeCheckChannelExpression(expression, manager)
+
+	case *ast.SpreadExpression:
+		dataType = spreadError(expression)
+
 	default:
 		log.Fatal(errors.DevError("(Type checker) Unexpected expression type: " + expr.String()))
 	}
@@ -141,32 +167,101 @@ func TypeCheckTypeExpression(expr ast.Expression, manager *modules.ModuleManager
 }
 
 func typeCheckAssignmentExpression(assignment *ast.AssignmentExpression, manager *modules.ModuleManager) types.ValidType {
+	switch assignment.Assignee.(type) {
+	case *ast.TupleExpression, *ast.ListLiteral:
+		if assignment.Operation != "=" {
+			return types.Error(fmt.Sprintf("Cannot use %q to assign to several values at once", assignment.Operation), assignment)
+		}
+
+		expressionType := typeCheckExpression(assignment.Value, manager)
+		if expressionType.String() == "TypeError" {
+			return expressionType
+		}
+		return typeCheckDestructuringAssignment(assignment.Assignee, expressionType, assignment, manager)
+	}
+
+	dataType := typeCheckAssignee(assignment.Assignee, assignment, manager)
+	if dataType.String() == "TypeError" {
+		return dataType
+	}
+
+	var expressionType types.ValidType
+	if assignment.Operation == "=" {
+		expressionType = typeCheckExpression(assignment.Value, manager)
+	} else {
+		operation := &ast.BinaryOperation{
+			Left:     assignment.Assignee,
+			Right:    assignment.Value,
+			Operator: assignment.Operation[:len(assignment.Operation)-1],
+			BaseNode: ast.BaseNode{Token: assignment.Token},
+		}
+		expressionType = typeCheckBinaryOperation(operation, manager)
+		assignment.Method = operation.Method
+	}
+	if expressionType.String() == "TypeError" {
+		return expressionType
+	}
+	correctType := dataType.Valid(expressionType)
+
+	if correctType {
+		return dataType
+	}
+
+	return types.Error(fmt.Sprintf("Type %q is not assignable to type %q", expressionType, dataType), assignment)
+}
+
+// Works out the type of value that can be assigned to an expression
+func typeCheckAssignee(assignee ast.Expression, assignment *ast.AssignmentExpression, manager *modules.ModuleManager) types.ValidType {
 	var dataType types.ValidType
-	if assignment.Assignee.Type() == "Identifier" {
-		symbolName := assignment.Assignee.(*ast.Identifier).Symbol
+	if assignee.Type() == "Identifier" {
+		symbolName := assignee.(*ast.Identifier).Symbol
 
-		dataType = manager.SymbolTable.GetSymbol(symbolName)
+		dataType = manager.SymbolTable.DeclaredSymbol(symbolName)
+		manager.SymbolTable.ResetNarrowing(symbolName)
 
-	} else if assignment.Assignee.Type() == "IndexExpression" {
-		index := assignment.Assignee.(*ast.IndexExpression)
+	} else if assignee.Type() == "IndexExpression" {
+		index := assignee.(*ast.IndexExpression)
 		leftType := typeCheckExpression(index.Left, manager)
 		if leftType.String() == "TypeError" {
 			return leftType
 		}
+		if index.Index == nil {
+			return types.Error(fmt.Sprintf("Cannot index type %q with a type", leftType), index)
+		}
 		indexType := typeCheckExpression(index.Index, manager)
 		if indexType.String() == "TypeError" {
 			return indexType
 		}
 
 		dataType = leftType.IndexBy(indexType)
-	} else if assignment.Assignee.Type() == "MemberExpression" {
-		member := assignment.Assignee.(*ast.MemberExpression)
+	} else if assignee.Type() == "MemberExpression" {
+		member := assignee.(*ast.MemberExpression)
+		if member.Optional {
+			return types.Error("Cannot assign to an optional member access", assignment)
+		}
 		leftType := typeCheckExpression(member.Left, manager)
 		if leftType.String() == "TypeError" {
 			return leftType
 		}
+		if pointer, isPointer := leftType.(*types.Pointer); isPointer && pointer.Const {
+			return types.Error("Cannot assign data to constant value", assignment)
+		}
 
 		dataType = types.Member(leftType, member.Member, member.IsNumberMember, manager.Id)
+	} else if deref, ok := assignee.(*ast.UnaryOperation); ok && deref.Operator == "*" && !deref.Postfix {
+		pointerType := typeCheckExpression(deref.Value, manager)
+		if pointerType.String() == "TypeError" {
+			return pointerType
+		}
+		pointer, isPointer := pointerType.(*types.Pointer)
+		if !isPointer {
+			return types.Error(fmt.Sprintf("Cannot dereference value of type %q, it is not a pointer", pointerType), deref)
+		}
+		if pointer.Const {
+			return types.Error("Cannot assign data to constant value", assignment)
+		}
+
+		dataType = pointer.DataType
 	} else {
 		return types.Error("Can only assign values to variables", assignment)
 	}
@@ -179,17 +274,62 @@ func typeCheckAssignmentExpression(assignment *ast.AssignmentExpression, manager
 		return types.Error("Cannot assign data to constant value", assignment)
 	}
 
-	expressionType := typeCheckExpression(assignment.Value, manager)
-	if expressionType.String() == "TypeError" {
-		return expressionType
-	}
-	correctType := dataType.Valid(expressionType)
+	return dataType
+}
 
-	if correctType {
-		return dataType
+// Checks each part of a value can be assigned to the matching element of a tuple or list of assignees
+func typeCheckDestructuringAssignment(assignee ast.Expression, dataType types.ValidType, assignment *ast.AssignmentExpression, manager *modules.ModuleManager) types.ValidType {
+	var elements []ast.Expression
+	var elemTypes []types.ValidType
+
+	switch target := assignee.(type) {
+	case *ast.TupleExpression:
+		tuple, isTuple := dataType.(*types.Tuple)
+		if !isTuple {
+			return types.Error(fmt.Sprintf("Cannot destructure type %q into a tuple", dataType), assignee)
+		}
+		if len(tuple.Members) != len(target.Members) {
+			return types.Error(fmt.Sprintf("Cannot destructure type %q into %d values, it has %d", dataType, len(target.Members), len(tuple.Members)), assignee)
+		}
+		elements = target.Members
+		elemTypes = tuple.Members
+
+	case *ast.ListLiteral:
+		var elemType types.ValidType
+		switch list := dataType.(type) {
+		case *types.ArrayLiteral:
+			if list.Length != -1 && list.Length != len(target.Elements) {
+				return types.Error(fmt.Sprintf("Cannot destructure type %q into %d values, it has %d", dataType, len(target.Elements), list.Length), assignee)
+			}
+			elemType = list.ElemType
+		case *types.ListLiteral:
+			elemType = list.ElemType
+		default:
+			return types.Error(fmt.Sprintf("Cannot destructure type %q into a list", dataType), assignee)
+		}
+		elements = target.Elements
+		for range target.Elements {
+			elemTypes = append(elemTypes, elemType)
+		}
+
+	default:
+		targetType := typeCheckAssignee(assignee, assignment, manager)
+		if targetType.String() == "TypeError" {
+			return targetType
+		}
+		if !targetType.Valid(dataType) {
+			return types.Error(fmt.Sprintf("Type %q is not assignable to type %q", dataType, targetType), assignee)
+		}
+		return targetType
 	}
 
-	return types.Error(fmt.Sprintf("Type %q is not assignable to type %q", expressionType, dataType), assignment)
+	for i, element := range elements {
+		result := typeCheckDestructuringAssignment(element, elemTypes[i], assignment, manager)
+		if result.String() == "TypeError" {
+			return result
+		}
+	}
+	return dataType
 }
 
 func typeCheckFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManager) types.ValidType {
@@ -200,25 +340,51 @@ func typeCheckFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManage
 			return typeCheckTupleStructExpression(structType, call, manager)
 		}
 
-		if builtin, ok := registry.Builtins[name]; ok {
-			if len(builtin.Parameters) != len(call.Args) {
-				if len(call.Args) < len(builtin.Parameters) {
-
-					return types.Error(fmt.Sprintf("Missing argument for function %q", name), call)
-				}
-				return types.Error(fmt.Sprintf("Extra argument passed to function %q", name), call)
+		// Declarations shadow builtins, so adding a builtin doesn't break programs already using its name
+		if builtin, ok := registry.Builtins[name]; ok && !manager.SymbolTable.Exists(name) {
+			args, rest, err := matchArguments(name, builtin.Parameters, nil, builtin.Optional, builtin.Variadic, call)
+			if err != nil {
+				return err
 			}
 
+			argTypes := make([]types.ValidType, len(builtin.Parameters))
 			for i, param := range builtin.Parameters {
-				arg := typeCheckExpression(call.Args[i], manager)
+				if args[i] == nil {
+					continue
+				}
+				arg := typeCheckExpression(args[i], manager)
 				if arg.String() == "TypeError" {
 					return arg
 				}
-				if !param.Valid(arg) {
+				correctType := param.Valid(arg)
+				argTypes[i] = arg
+				// Parameters such as lists of any type take the rest of their type from the argument
+				if partial, ok := param.(types.PartialType); ok {
+					argTypes[i], correctType = partial.Infer(arg)
+				}
+
+				if !correctType {
 					return types.Error(fmt.Sprintf("Invalid arguments passed to function %q: Type %q is not a valid argument for parameter of type %q", name, arg, param), call)
 				}
 			}
 
+			if builtin.Variadic {
+				err := typeCheckVariadicArgs(name, builtin.Parameters[len(builtin.Parameters)-1], rest, call, manager)
+				if err.String() == "TypeError" {
+					return err
+				}
+			}
+
+			if builtin.Infer != nil {
+				// These builtins call the functions they are passed
+				manager.SymbolTable.ResetNonLocalNarrowing()
+				returnType, message := builtin.Infer(argTypes)
+				if message != "" {
+					return types.Error(fmt.Sprintf("Invalid arguments passed to function %q: %s", name, message), call)
+				}
+				return returnType
+			}
+
 			return builtin.ReturnType
 		}
 	}
@@ -236,28 +402,34 @@ func typeCheckFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManage
 	function, ok := callVar.(*types.Function)
 
 	if !ok {
+		if isOptional(callVar) {
+			return optionalError(callVar, "calling it", call.Left)
+		}
 		return types.Error(fmt.Sprintf("%q is not a function", call.Left.String()), call)
 	}
 
 	name := function.Name
+	if function.Instantiate != nil {
+		return types.Error(fmt.Sprintf("Function %q needs a type argument, as in %s[T](...)", name, call.Left), call)
+	}
 
-	if len(function.Parameters) != len(call.Args) {
-		if len(call.Args) < len(function.Parameters) {
-
-			return types.Error(fmt.Sprintf("Missing argument for function %q", name), call)
-		}
-		return types.Error(fmt.Sprintf("Extra argument passed to function %q", name), call)
+	args, rest, err := matchArguments(name, function.Parameters, function.ParameterNames, function.Defaults, function.Variadic, call)
+	if err != nil {
+		return err
 	}
 
 	for i, param := range function.Parameters {
-		arg := typeCheckExpression(call.Args[i], manager)
+		if args[i] == nil {
+			continue
+		}
+		arg := typeCheckExpression(args[i], manager)
 		if arg.String() == "TypeError" {
 			return arg
 		}
 
 		var correctType = param.Valid(arg)
 
-		if partial, ok := param.(types.PartialType); ok {
+		if partial, ok := param.(types.PartialType); !correctType && ok {
 			param, correctType = partial.Infer(arg)
 		}
 
@@ -266,10 +438,123 @@ func typeCheckFunctionCall(call *ast.FunctionCall, manager *modules.ModuleManage
 		}
 	}
 
+	if function.Variadic {
+		err := typeCheckVariadicArgs(name, function.Parameters[len(function.Parameters)-1], rest, call, manager)
+		if err.String() == "TypeError" {
+			return err
+		}
+	}
+
+	// The function could reassign any variable it can see, so narrowing no longer holds for them
+	manager.SymbolTable.ResetNonLocalNarrowing()
 	return function.ReturnType
 }
 
+// Matches the positional and named arguments of a call to the parameters they are passed to.
+// Parameters left to their default value get a nil argument, and any extra arguments to a
+// variadic function are returned separately
+func matchArguments(name string, params []types.ValidType, paramNames []string, defaults int, variadic bool, call *ast.FunctionCall) (args, rest []ast.Expression, err *types.TypeError) {
+	fixed := len(params)
+	if variadic {
+		fixed--
+	}
+
+	positional := call.Args
+	if len(positional) > fixed {
+		if !variadic {
+			return nil, nil, types.Error(fmt.Sprintf("Extra argument passed to function %q", name), call)
+		}
+		rest = positional[fixed:]
+		positional = positional[:fixed]
+	}
+
+	for _, arg := range positional {
+		if spread, ok := arg.(*ast.SpreadExpression); ok {
+			return nil, nil, spreadError(spread)
+		}
+	}
+
+	args = make([]ast.Expression, len(params))
+	copy(args, positional)
+
+	for _, namedArg := range call.NamedArgs {
+		index := -1
+		for i, paramName := range paramNames {
+			if paramName == namedArg.Name {
+				index = i
+				break
+			}
+		}
+
+		if index == -1 {
+			return nil, nil, types.Error(fmt.Sprintf("Function %q has no parameter named %q", name, namedArg.Name), namedArg.Value)
+		}
+		if args[index] != nil || (index == fixed && len(rest) != 0) {
+			return nil, nil, types.Error(fmt.Sprintf("Argument %q passed to function %q more than once", namedArg.Name, name), namedArg.Value)
+		}
+		args[index] = namedArg.Value
+	}
+
+	for i, arg := range args[:fixed-defaults] {
+		if arg == nil {
+			if i < len(paramNames) {
+				return nil, nil, types.Error(fmt.Sprintf("Missing argument %q for function %q", paramNames[i], name), call)
+			}
+			return nil, nil, types.Error(fmt.Sprintf("Missing argument for function %q", name), call)
+		}
+	}
+
+	return args, rest, nil
+}
+
+// Checks the arguments collected by a variadic parameter, spreading any lists.
+// Mismatched arguments are reported at the call, as a piped argument comes from an earlier stage
+func typeCheckVariadicArgs(name string, param types.ValidType, rest []ast.Expression, call *ast.FunctionCall, manager *modules.ModuleManager) types.ValidType {
+	elemType := param.(*types.ListLiteral).ElemType
+
+	for _, arg := range rest {
+		value := arg
+		if spread, ok := arg.(*ast.SpreadExpression); ok {
+			value = spread.Value
+		}
+
+		argType := typeCheckExpression(value, manager)
+		if argType.String() == "TypeError" {
+			return argType
+		}
+
+		if value != arg {
+			switch list := argType.(type) {
+			case *types.ListLiteral:
+				argType = list.ElemType
+			case *types.ArrayLiteral:
+				argType = list.ElemType
+			default:
+				return types.Error(fmt.Sprintf("Cannot spread value of type %q, it is not a list", argType), arg)
+			}
+
+			// The element type of an empty list is still unknown, so it fits anywhere
+			if _, isInfer := argType.(*types.Infer); isInfer {
+				continue
+			}
+		}
+
+		if !elemType.Valid(argType) {
+			return types.Error(fmt.Sprintf("Invalid arguments passed to function %q: Type %q is not a valid argument for parameter of type %q", name, argType, elemType), call)
+		}
+	}
+
+	return param
+}
+
+func spreadError(spread *ast.SpreadExpression) *types.TypeError {
+	return types.Error("Spread arguments can only be passed to a variadic parameter", spread)
+}
+
 func typeCheckTupleStructExpression(tuple *types.TupleStruct, instance *ast.FunctionCall, manager *modules.ModuleManager) types.ValidType {
+	if len(instance.NamedArgs) != 0 {
+		return types.Error("Tuple struct expressions do not take named arguments", instance.NamedArgs[0].Value)
+	}
 	if len(tuple.Members) != len(instance.Args) {
 		return types.Error("Tuple struct expression incompatible with type", instance)
 	}
@@ -319,12 +604,60 @@ func typeCheckList(list *ast.ListLiteral, manager *modules.ModuleManager) types.
 	}
 }
 
+func typeCheckListComprehension(comp *ast.ListComprehension, manager *modules.ModuleManager) types.ValidType {
+	iterableType := typeCheckExpression(comp.Iterable, manager)
+	if iterableType.String() == "TypeError" {
+		return iterableType
+	}
+
+	elemType, ok := types.ElemTypeOf(iterableType)
+	if !ok {
+		if isOptional(iterableType) {
+			return optionalError(iterableType, "iterating over it", comp.Iterable)
+		}
+		return types.Error(fmt.Sprintf("Cannot iterate over value of type %q", iterableType), comp.Iterable)
+	}
+
+	newScope := symbols.NewChild(manager.SymbolTable, symbols.GENERIC_SCOPE)
+	manager.EnterScope(newScope)
+
+	err := manager.SymbolTable.RegisterSymbol(comp.Variable, elemType, false)
+	if err != nil {
+		err.Line = comp.Token.Line
+		err.Column = comp.Token.Column
+		return err
+	}
+
+	if comp.Condition != nil {
+		conditionType := typeCheckExpression(comp.Condition, manager)
+		if conditionType.String() == "TypeError" {
+			return conditionType
+		}
+		whenTrue, _ := narrowCondition(comp.Condition, manager)
+		applyNarrowing(whenTrue, manager)
+	}
+
+	valueType := typeCheckExpression(comp.Value, manager)
+	if valueType.String() == "TypeError" {
+		return valueType
+	}
+	if isVoid(valueType) {
+		return types.Error("Cannot collect void values into a list", comp.Value)
+	}
+	manager.ExitScope()
+
+	if pseudo, ok := valueType.(types.PseudoType); ok {
+		valueType = pseudo.ToReal()
+	}
+	return &types.ListLiteral{ElemType: valueType}
+}
+
 func typeCheckMap(maplit *ast.MapLiteral, manager *modules.ModuleManager) types.ValidType {
 	keyTypes := []types.ValidType{}
 	valueTypes := []types.ValidType{}
 
-	for key, value := range maplit.Elements {
-		keyType := typeCheckExpression(key, manager)
+	for _, element := range maplit.Elements {
+		keyType := typeCheckExpression(element.Key, manager)
 		if keyType.String() == "TypeError" {
 			return keyType
 		}
@@ -340,7 +673,7 @@ func typeCheckMap(maplit *ast.MapLiteral, manager *modules.ModuleManager) types.
 			keyTypes = append(keyTypes, keyType)
 		}
 
-		valueType := typeCheckExpression(value, manager)
+		valueType := typeCheckExpression(element.Value, manager)
 		if valueType.String() == "TypeError" {
 			return valueType
 		}
@@ -370,6 +703,21 @@ func typeCheckIndexExpression(indexExpr *ast.IndexExpression, manager *modules.M
 		return leftType
 	}
 
+	if fn, ok := leftType.(*types.Function); ok && fn.Instantiate != nil {
+		if indexExpr.TypeArgument == nil {
+			return types.Error(fmt.Sprintf("Expected a type argument for function %q", fn.Name), indexExpr)
+		}
+		typeArgument := TypeCheckType(indexExpr.TypeArgument, manager)
+		if typeArgument.String() == "TypeError" {
+			return typeArgument
+		}
+		return fn.Instantiate(typeArgument)
+	}
+
+	if indexExpr.Index == nil {
+		return types.Error(fmt.Sprintf("Cannot index type %q with a type", leftType), indexExpr)
+	}
+
 	indexType := typeCheckExpression(indexExpr.Index, manager)
 	if indexType.String() == "TypeError" {
 		return indexType
@@ -377,26 +725,238 @@ func typeCheckIndexExpression(indexExpr *ast.IndexExpression, manager *modules.M
 
 	resultType := leftType.IndexBy(indexType)
 	if resultType == nil {
+		if isOptional(leftType) {
+			return optionalError(leftType, "indexing it", indexExpr.Left)
+		}
 		return types.Error(fmt.Sprintf("Type %q is not indexable with type %q", leftType.String(), indexType.String()), indexExpr)
 	}
 
 	return resultType
 }
 
+func typeCheckIfExpression(ifExpr *ast.IfExpression, manager *modules.ModuleManager) types.ValidType {
+	branches, err := typeCheckIfBranches(ifExpr.Statement, manager)
+	if err != nil {
+		return err
+	}
+
+	var dataType types.ValidType
+	for _, branch := range branches {
+		if dataType == nil {
+			dataType = branch
+			continue
+		}
+
+		// A missing else just makes the value optional
+		if _, isNull := branch.(*types.NullLiteral); isNull && isVoid(dataType) {
+			continue
+		}
+		if isVoid(branch) != isVoid(dataType) {
+			return types.Error(fmt.Sprintf("Branches of if expression have mismatched types %q and %q", dataType, branch), ifExpr)
+		}
+		dataType = unifyTypes(dataType, branch)
+	}
+
+	// Every branch returns, so the expression never gives a value
+	if dataType == nil {
+		return &types.Void{}
+	}
+	return dataType
+}
+
+func typeCheckBlockExpression(block *ast.BlockExpression, manager *modules.ModuleManager) types.ValidType {
+	newScope := symbols.NewChild(manager.SymbolTable, symbols.GENERIC_SCOPE)
+	manager.EnterScope(newScope)
+
+	defer manager.ExitScope()
+
+	return typeCheckBlock(block.Body, manager)
+}
+
+func isVoid(dataType types.ValidType) bool {
+	_, isVoid := dataType.(*types.Void)
+	return isVoid
+}
+
+// Finds a type which can hold values of both types, making a union if neither contains the other
+func unifyTypes(a, b types.ValidType) types.ValidType {
+	// Declarations only make the top level of a type real, so an untyped value is made real before it is wrapped
+	if _, isNull := b.(*types.NullLiteral); isNull {
+		return types.MakeOptional(types.ToReal(a))
+	}
+	if _, isNull := a.(*types.NullLiteral); isNull {
+		return types.MakeOptional(types.ToReal(b))
+	}
+	if a.Valid(b) {
+		return a
+	}
+	if b.Valid(a) {
+		return b
+	}
+
+	a, b = types.ToReal(a), types.ToReal(b)
+
+	members := []types.ValidType{}
+	for _, dataType := range []types.ValidType{a, b} {
+		if union, isUnion := dataType.(*types.Union); isUnion {
+			members = append(members, union.Types...)
+		} else {
+			members = append(members, dataType)
+		}
+	}
+	return types.MakeUnion(members...)
+}
+
+func typeCheckSliceExpression(slice *ast.SliceExpression, manager *modules.ModuleManager) types.ValidType {
+	leftType := typeCheckExpression(slice.Left, manager)
+	if leftType.String() == "TypeError" {
+		return leftType
+	}
+
+	for _, bound := range []ast.Expression{slice.Start, slice.End} {
+		if bound == nil {
+			continue
+		}
+		boundType := typeCheckExpression(bound, manager)
+		if boundType.String() == "TypeError" {
+			return boundType
+		}
+		if !(&types.IntLiteral{}).Valid(boundType) {
+			return types.Error(fmt.Sprintf("Slice bounds must be of type \"int\", not %q", boundType), bound)
+		}
+	}
+
+	switch ty := leftType.(type) {
+	case *types.ListLiteral:
+		return &types.ListLiteral{ElemType: ty.ElemType}
+
+	// Slices of arrays don't have a known length, so they are lists
+	case *types.ArrayLiteral:
+		elemType := ty.ElemType
+		if pseudo, ok := elemType.(types.PseudoType); ok {
+			elemType = pseudo.ToReal()
+		}
+		return &types.ListLiteral{ElemType: elemType}
+
+	case *types.StringLiteral:
+		return &types.StringLiteral{}
+	}
+
+	if isOptional(leftType) {
+		return optionalError(leftType, "slicing it", slice.Left)
+	}
+	return types.Error(fmt.Sprintf("Type %q cannot be sliced", leftType), slice)
+}
+
 func typeCheckMemberExpression(memberExpr *ast.MemberExpression, manager *modules.ModuleManager) types.ValidType {
+	if associated := typeCheckAssociatedFunction(memberExpr, manager); associated != nil {
+		return associated
+	}
+
 	leftType := typeCheckExpression(memberExpr.Left, manager)
 	if leftType.String() == "TypeError" {
 		return leftType
 	}
 
+	// `?.` is lexed as one token, but after an error it means propagating the error with `?`, then accessing the member
+	if _, isError := leftType.(*types.ErrorType); isError && memberExpr.Optional {
+		memberExpr.Left = &ast.UnaryOperation{
+			Value:    memberExpr.Left,
+			Operator: "?",
+			BaseNode: ast.BaseNode{Token: memberExpr.Left.GetToken()},
+			Postfix:  true,
+		}
+		memberExpr.Optional = false
+		return typeCheckMemberExpression(memberExpr, manager)
+	}
+
+	if memberExpr.Optional {
+		optional, isOptional := leftType.(*types.Optional)
+		if !isOptional {
+			return types.Error(fmt.Sprintf("Cannot use \"?.\" on non-optional type %q", leftType), memberExpr)
+		}
+		leftType = optional.DataType
+	}
+
 	resultType := types.Member(leftType, memberExpr.Member, memberExpr.IsNumberMember, manager.Id)
+	if err, isErr := resultType.(*types.TypeError); isErr {
+		err.Line = memberExpr.Token.Line
+		err.Column = memberExpr.Token.Column
+		return err
+	}
 	if resultType == nil {
+		if isOptional(leftType) {
+			return optionalError(leftType, fmt.Sprintf("accessing member %q", memberExpr.Member), memberExpr.Left)
+		}
 		return types.Error(fmt.Sprintf("Type %q does not have member %q, or it is private", leftType.String(), memberExpr.Member), memberExpr)
 	}
 
+	// Methods with pointer receivers can modify the value they're called on
+	if method, isMethod := resultType.(*types.Function); isMethod && method.PointerReceiver {
+		pointer, isPointer := leftType.(*types.Pointer)
+		if (isPointer && pointer.Const) || (!isPointer && leftType.Constant()) {
+			return types.Error(fmt.Sprintf("Cannot call method %q on a constant value, as it can modify it", memberExpr.Member), memberExpr)
+		}
+	}
+
+	if memberExpr.Optional {
+		return types.MakeOptional(resultType)
+	}
 	return resultType
 }
 
+// Finds the associated function accessed through a type name (T.name), if the left side is a type
+func typeCheckAssociatedFunction(memberExpr *ast.MemberExpression, manager *modules.ModuleManager) types.ValidType {
+	if memberExpr.Optional {
+		return nil
+	}
+
+	var ownerType types.ValidType
+	switch left := memberExpr.Left.(type) {
+	case *ast.Identifier:
+		// Variables shadow types of the same name
+		if manager.SymbolTable.GetSymbol(left.Symbol).String() != "TypeError" {
+			return nil
+		}
+		ownerType = manager.SymbolTable.GetType(left.Symbol)
+
+	case *ast.MemberExpression:
+		ty, isType := doTypeCheckExpression(left, manager).(*types.Type)
+		if !isType {
+			return nil
+		}
+		ownerType = ty.DataType
+
+	default:
+		return nil
+	}
+
+	if ownerType.String() == "TypeError" {
+		return nil
+	}
+
+	fn := types.AssociatedFunction(ownerType, memberExpr.Member, manager.Id)
+	if fn == nil {
+		// Unit structs are values too, so their methods can still be accessed
+		if _, isUnit := ownerType.(*types.UnitStruct); isUnit {
+			return nil
+		}
+		return types.Error(fmt.Sprintf("Type %q does not have associated function %q, or it is private", ownerType, memberExpr.Member), memberExpr)
+	}
+
+	memberExpr.Associated = ownerType
+	return fn
+}
+
+func isOptional(dataType types.ValidType) bool {
+	_, ok := dataType.(*types.Optional)
+	return ok
+}
+
+func optionalError(dataType types.ValidType, use string, node ast.Node) *types.TypeError {
+	return types.Error(fmt.Sprintf("Value of optional type %q might be null, check it with \"??\" or \"!\" before %s", dataType, use), node)
+}
+
 func typeCheckStructExpression(structExpr *ast.StructExpression, manager *modules.ModuleManager) types.ValidType {
 	definedType := TypeCheckTypeExpression(structExpr.InstanceOf, manager)
 	if definedType.String() == "TypeError" {
@@ -408,20 +968,25 @@ func typeCheckStructExpression(structExpr *ast.StructExpression, manager *module
 		return types.Error(fmt.Sprintf("Cannot instantiate %q, it is not a struct", definedType), structExpr)
 	}
 
-	members := map[string]types.StructField{}
+	instanceType := &types.Struct{Name: structType.Name}
 
-	for name, member := range structExpr.Members {
-		dataType := typeCheckExpression(member, manager)
+	for _, member := range structExpr.Members {
+		if _, exists := instanceType.Field(member.Name); exists {
+			return types.Error(fmt.Sprintf("Field %q is given more than once", member.Name), member.Value)
+		}
+
+		dataType := typeCheckExpression(member.Value, manager)
 		if dataType.String() == "TypeError" {
 			return dataType
 		}
 
-		members[name] = types.StructField{Type: dataType}
+		instanceType.Members = append(instanceType.Members, types.StructField{Name: member.Name, Type: dataType})
 	}
 
-	instanceType := &types.Struct{
-		Name:    structType.Name,
-		Members: members,
+	for _, field := range structType.Members {
+		if _, hasMember := instanceType.Field(field.Name); !hasMember && !types.HasZeroValue(field.Type) {
+			return types.Error(fmt.Sprintf("Missing value for field %q, as type %q has no zero value", field.Name, field.Type), structExpr)
+		}
 	}
 
 	if !structType.Valid(instanceType) {
@@ -479,3 +1044,32 @@ func typeCheckTypeCheckExpression(expr *ast.TypeCheckExpression, manager *module
 
 	return &types.BoolLiteral{}
 }
+
+func typeCheckSpawnExpression(spawn *ast.SpawnExpression, manager *modules.ModuleManager) types.ValidType {
+	resultType := typeCheckExpression(spawn.Call, manager)
+	if resultType.String() == "TypeError" {
+		return resultType
+	}
+
+	return &types.Channel{ElemType: resultType}
+}
+
+func typeCheckChannelExpression(channel *ast.ChannelExpression, manager *modules.ModuleManager) types.ValidType {
+	elemType := TypeCheckType(channel.ElemType, manager)
+	if elemType.String() == "TypeError" {
+		return elemType
+	}
+
+	if channel.Capacity != nil {
+		capacityType := typeCheckExpression(channel.Capacity, manager)
+		if capacityType.String() == "TypeError" {
+			return capacityType
+		}
+
+		if !(&types.IntLiteral{}).Valid(capacityType) {
+			return types.Error(fmt.Sprintf("Channel capacity must be an integer, got %q", capacityType), channel.Capacity)
+		}
+	}
+
+	return &types.Channel{ElemType: elemType}
+}
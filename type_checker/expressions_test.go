@@ -0,0 +1,59 @@
+package typechecker
+
+import (
+	"testing"
+
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+func TestIfExpressionWithoutElseHasRealType(t *testing.T) {
+	source := `
+		fn f(): boolean { return true }
+		var v = if f() { 1 }
+		v = 2.5`
+	if typeCheckSource(t, source) == nil {
+		t.Error("expected a type error assigning a float to an optional int")
+	}
+
+	valid := `
+		fn f(): boolean { return true }
+		var v = if f() { 1 }
+		v = 2
+		v = null`
+	if err := typeCheckSource(t, valid); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBlockExpressionExitsScopeOnError(t *testing.T) {
+	manager, err := checkSource(t, `const x = { const y: int = "a"; 1 }`)
+	if err == nil {
+		t.Fatal("expected a type error in the block")
+	}
+	if manager.SymbolTable.Parent != nil {
+		t.Error("expected the block's scope to be exited")
+	}
+}
+
+func TestPipelineErrorsPointAtStage(t *testing.T) {
+	sources := map[string]string{
+		"extra argument": `
+			fn f(x: int): int { return x }
+			const r = 1
+				|> f()
+				|> f(2)`,
+		"variadic argument": `
+			fn sum(...xs: int): int { return 0 }
+			fn name(): string { return "a" }
+			const r = name()
+				|> sum()
+				|> sum()`,
+	}
+
+	for name, source := range sources {
+		err := typeCheckSource(t, source)
+		if typeErr, ok := err.(*types.TypeError); !ok || typeErr.Line != 5 {
+			t.Errorf("%s: expected an error at the stage on line 5, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package typechecker
+
+import (
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// The types variables are known to have, depending on the outcome of a condition
+type narrowing map[string]types.ValidType
+
+// Works out what a condition tells us about the types of variables when it is true and when it is false.
+// The condition must already be type checked
+func narrowCondition(condition ast.Expression, manager *modules.ModuleManager) (whenTrue, whenFalse narrowing) {
+	switch cond := condition.(type) {
+	case *ast.TypeCheckExpression:
+		ident, ok := cond.Left.(*ast.Identifier)
+		if !ok {
+			return nil, nil
+		}
+
+		target := cond.DataType.GetType()
+		matching, rest := splitType(manager.SymbolTable.GetSymbol(ident.Symbol), target.Valid)
+		if len(matching) == 0 {
+			matching = []types.ValidType{target}
+		}
+		return narrowTo(ident.Symbol, matching), narrowTo(ident.Symbol, rest)
+
+	case *ast.BinaryOperation:
+		switch cond.Operator {
+		case "==", "!=":
+			ident, ok := cond.Left.(*ast.Identifier)
+			_, isNull := cond.Right.(*ast.NullLiteral)
+			if !ok || !isNull {
+				ident, ok = cond.Right.(*ast.Identifier)
+				_, isNull = cond.Left.(*ast.NullLiteral)
+			}
+			if !ok || !isNull {
+				return nil, nil
+			}
+
+			null, rest := splitType(manager.SymbolTable.GetSymbol(ident.Symbol), (&types.NullLiteral{}).Valid)
+			if len(null) == 0 {
+				return nil, nil
+			}
+			if cond.Operator == "==" {
+				return narrowTo(ident.Symbol, null), narrowTo(ident.Symbol, rest)
+			}
+			return narrowTo(ident.Symbol, rest), narrowTo(ident.Symbol, null)
+
+		case "&&":
+			leftTrue, leftFalse := narrowCondition(cond.Left, manager)
+			rightTrue, rightFalse := narrowWithin(cond.Right, leftTrue, manager)
+			return merge(leftTrue, rightTrue), either(leftFalse, rightFalse)
+
+		case "||":
+			leftTrue, leftFalse := narrowCondition(cond.Left, manager)
+			rightTrue, rightFalse := narrowWithin(cond.Right, leftFalse, manager)
+			return either(leftTrue, rightTrue), merge(leftFalse, rightFalse)
+		}
+
+	case *ast.UnaryOperation:
+		if cond.Operator == "!" && !cond.Postfix {
+			whenTrue, whenFalse := narrowCondition(cond.Value, manager)
+			return whenFalse, whenTrue
+		}
+	}
+
+	return nil, nil
+}
+
+// Narrows a condition which is only evaluated once some other narrowing is known to hold
+func narrowWithin(condition ast.Expression, known narrowing, manager *modules.ModuleManager) (whenTrue, whenFalse narrowing) {
+	enterNarrowedScope(known, manager)
+	defer manager.ExitScope()
+	return narrowCondition(condition, manager)
+}
+
+// Enters a new scope in which the narrowed variables have their narrowed types
+func enterNarrowedScope(narrowed narrowing, manager *modules.ModuleManager) {
+	manager.EnterScope(symbols.NewChild(manager.SymbolTable, symbols.GENERIC_SCOPE))
+	applyNarrowing(narrowed, manager)
+}
+
+func applyNarrowing(narrowed narrowing, manager *modules.ModuleManager) {
+	for name, dataType := range narrowed {
+		manager.SymbolTable.Narrow(name, dataType)
+	}
+}
+
+// Forgets what is known about every variable a loop assigns to, since that
+// assignment may already have happened by the time the loop comes round again
+func forgetAssigned(loop ast.Node, manager *modules.ModuleManager) {
+	ast.Inspect(loop, func(node ast.Node) bool {
+		if assignment, ok := node.(*ast.AssignmentExpression); ok {
+			forgetAssignee(assignment.Assignee, manager)
+		}
+		return true
+	})
+}
+
+func forgetAssignee(assignee ast.Expression, manager *modules.ModuleManager) {
+	switch target := assignee.(type) {
+	case *ast.Identifier:
+		manager.SymbolTable.ResetNarrowing(target.Symbol)
+	case *ast.TupleExpression:
+		for _, member := range target.Members {
+			forgetAssignee(member, manager)
+		}
+	case *ast.ListLiteral:
+		for _, element := range target.Elements {
+			forgetAssignee(element, manager)
+		}
+	}
+}
+
+// Splits a type into the possible types which satisfy a predicate, and those which don't
+func splitType(dataType types.ValidType, predicate func(types.ValidType) bool) (matching, rest []types.ValidType) {
+	possible := []types.ValidType{dataType}
+	switch ty := dataType.(type) {
+	case *types.Union:
+		possible = ty.Types
+	case *types.Optional:
+		possible = []types.ValidType{ty.DataType, &types.NullLiteral{}}
+	case *types.ErrorType:
+		possible = append([]types.ValidType{ty.ResultType}, ty.Errors()...)
+	case *types.TypeError:
+		return nil, nil
+	}
+
+	for _, possibleType := range possible {
+		if predicate(possibleType) {
+			matching = append(matching, possibleType)
+		} else {
+			rest = append(rest, possibleType)
+		}
+	}
+	return matching, rest
+}
+
+func narrowTo(name string, possible []types.ValidType) narrowing {
+	if len(possible) == 0 {
+		return nil
+	}
+	return narrowing{name: types.MakeUnion(possible...)}
+}
+
+// Both narrowings hold. The second is worked out knowing the first, so it takes priority
+func merge(first, second narrowing) narrowing {
+	result := narrowing{}
+	for name, dataType := range first {
+		result[name] = dataType
+	}
+	for name, dataType := range second {
+		result[name] = dataType
+	}
+	return result
+}
+
+// One of the narrowings holds, so we only know about variables narrowed by both
+func either(first, second narrowing) narrowing {
+	result := narrowing{}
+	for name, firstType := range first {
+		secondType, ok := second[name]
+		if !ok {
+			continue
+		}
+
+		if firstType.Valid(secondType) {
+			result[name] = firstType
+		} else if secondType.Valid(firstType) {
+			result[name] = secondType
+		} else {
+			result[name] = types.MakeUnion(firstType, secondType)
+		}
+	}
+	return result
+}
+
+// Whether a block always returns from the function, so nothing after it can run
+func alwaysReturns(body []ast.Statement) bool {
+	for _, statement := range body {
+		switch stmt := statement.(type) {
+		case *ast.ReturnStatement:
+			return true
+
+		case *ast.IfStatement:
+			if ifAlwaysReturns(stmt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ifAlwaysReturns(ifStatement *ast.IfStatement) bool {
+	if !alwaysReturns(ifStatement.Body) {
+		return false
+	}
+
+	switch elseStatement := ifStatement.Else.(type) {
+	case *ast.IfStatement:
+		return ifAlwaysReturns(elseStatement)
+	case *ast.ElseStatement:
+		return alwaysReturns(elseStatement.Body)
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package typechecker
+
+import "testing"
+
+func TestLoopForgetsNarrowingOfAssignedVariables(t *testing.T) {
+	sources := map[string]string{
+		"while": `
+			fn f(): int? { return 1 }
+			var v = f()
+			var i = 0
+			if v != null { while i < 3 { print(v + 1); v = null; i += 1 } }`,
+		"for": `
+			fn f(): int? { return 1 }
+			var v = f()
+			if v != null { for var i = 0; i < 3; i++ { print(v + 1); v = null } }`,
+	}
+
+	for name, source := range sources {
+		if typeCheckSource(t, source) == nil {
+			t.Errorf("%s loop: expected a type error using a variable reassigned to null later in the loop", name)
+		}
+	}
+}
+
+func TestLoopKeepsNarrowingOfUnassignedVariables(t *testing.T) {
+	source := `
+		fn f(): int? { return 1 }
+		var v = f()
+		if v != null { for var i = 0; i < 3; i++ { print(v + 1) } }`
+
+	if err := typeCheckSource(t, source); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCallForgetsNarrowingOfNonLocalVariables(t *testing.T) {
+	invalid := `
+		var v: int? = 1
+		fn reset() { v = null }
+		fn f() {
+			if v != null { reset(); print(v + 1) }
+		}`
+	if typeCheckSource(t, invalid) == nil {
+		t.Error("expected a type error using a global after a call which could reassign it")
+	}
+
+	valid := `
+		fn g() {}
+		fn f(p: int?) {
+			var local = p
+			const c = p
+			if local != null && c != null { g(); print(local + c) }
+		}`
+	if err := typeCheckSource(t, valid); err != nil {
+		t.Error(err)
+	}
+}
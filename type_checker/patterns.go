@@ -0,0 +1,113 @@
+package typechecker
+
+import (
+	"fmt"
+
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser/ast"
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
+
+// Declares the variables bound by a pattern, checking the pattern fits a value of the given type
+func typeCheckPattern(pattern ast.Pattern, dataType types.ValidType, constant bool, manager *modules.ModuleManager) types.ValidType {
+	switch pat := pattern.(type) {
+	case *ast.IdentifierPattern:
+		if pseudo, ok := dataType.(types.PseudoType); ok {
+			dataType = pseudo.ToReal()
+		}
+		pat.SetType(dataType)
+		if pat.Name == "_" {
+			return dataType
+		}
+
+		err := manager.SymbolTable.RegisterSymbol(pat.Name, dataType, constant)
+		if err != nil {
+			err.Line = pat.Token.Line
+			err.Column = pat.Token.Column
+			return err
+		}
+		return dataType
+
+	case *ast.TuplePattern:
+		memberTypes, err := tupleMembers(pat, dataType, manager)
+		if err != nil {
+			return err
+		}
+		if len(memberTypes) != len(pat.Elements) {
+			return types.Error(fmt.Sprintf("Cannot destructure type %q into %d values, it has %d", dataType, len(pat.Elements), len(memberTypes)), pat)
+		}
+
+		for i, element := range pat.Elements {
+			result := typeCheckPattern(element, memberTypes[i], constant, manager)
+			if result.String() == "TypeError" {
+				return result
+			}
+		}
+		return dataType
+
+	case *ast.ListPattern:
+		var elemType types.ValidType
+		switch list := dataType.(type) {
+		case *types.ArrayLiteral:
+			if list.Length != -1 && list.Length != len(pat.Elements) {
+				return types.Error(fmt.Sprintf("Cannot destructure type %q into %d values, it has %d", dataType, len(pat.Elements), list.Length), pat)
+			}
+			elemType = list.ElemType
+		case *types.ListLiteral:
+			elemType = list.ElemType
+		default:
+			return types.Error(fmt.Sprintf("Cannot destructure type %q with a list pattern", dataType), pat)
+		}
+
+		for _, element := range pat.Elements {
+			result := typeCheckPattern(element, elemType, constant, manager)
+			if result.String() == "TypeError" {
+				return result
+			}
+		}
+		return dataType
+
+	case *ast.StructPattern:
+		structType, isStruct := manager.SymbolTable.GetType(pat.Struct).(*types.Struct)
+		if !isStruct {
+			return types.Error(fmt.Sprintf("%q is not a struct", pat.Struct), pat)
+		}
+		if !structType.Valid(dataType) {
+			return types.Error(fmt.Sprintf("Cannot destructure type %q as struct %q", dataType, pat.Struct), pat)
+		}
+
+		for _, field := range pat.Fields {
+			fieldType := types.Member(dataType, field.Name, false, manager.Id)
+			if fieldType == nil {
+				return types.Error(fmt.Sprintf("Struct %q has no field %q", pat.Struct, field.Name), field.Pattern)
+			}
+
+			result := typeCheckPattern(field.Pattern, fieldType, constant, manager)
+			if result.String() == "TypeError" {
+				return result
+			}
+		}
+		return dataType
+	}
+
+	return types.Error("Invalid pattern", pattern)
+}
+
+func tupleMembers(pattern *ast.TuplePattern, dataType types.ValidType, manager *modules.ModuleManager) ([]types.ValidType, *types.TypeError) {
+	if pattern.Struct == "" {
+		tuple, isTuple := dataType.(*types.Tuple)
+		if !isTuple {
+			return nil, types.Error(fmt.Sprintf("Cannot destructure type %q with a tuple pattern", dataType), pattern)
+		}
+		return tuple.Members, nil
+	}
+
+	structType, isStruct := manager.SymbolTable.GetType(pattern.Struct).(*types.TupleStruct)
+	if !isStruct {
+		return nil, types.Error(fmt.Sprintf("%q is not a tuple struct", pattern.Struct), pattern)
+	}
+	if !structType.Valid(dataType) {
+		return nil, types.Error(fmt.Sprintf("Cannot destructure type %q as struct %q", dataType, pattern.Struct), pattern)
+	}
+	return dataType.(*types.TupleStruct).Members, nil
+}
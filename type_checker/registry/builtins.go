@@ -1,36 +1,203 @@
 package registry
 
-import "github.com/gearsdatapacks/libra/type_checker/types"
+import (
+	"fmt"
+
+	"github.com/gearsdatapacks/libra/type_checker/types"
+)
 
 type params = []types.ValidType
 
 type builtin struct {
 	Parameters params
+	// How many of the trailing parameters can be left out
+	Optional int
+	// Whether the last parameter is a list which collects any extra arguments
+	Variadic   bool
 	ReturnType types.ValidType
+	// Works out the return type from the argument types, for builtins which are generic over them.
+	// Parameters which are partial types are given as inferred from their argument.
+	// Returns an error message if the arguments don't fit together.
+	Infer func(args []types.ValidType) (types.ValidType, string)
 }
 
 var Builtins = map[string]builtin{}
 
+// The description of a type returned by typeof
+var TypeInfo = &types.Struct{Name: "TypeInfo"}
+
+// A named part of a type, such as a struct field or function parameter
+var TypeField = &types.Struct{Name: "TypeField"}
+
+func registerTypeInfo() {
+	TypeField.Members = []types.StructField{
+		{Name: "name", Type: stringType, Exported: true},
+		{Name: "type", Type: TypeInfo, Exported: true},
+	}
+
+	typeList := &types.ListLiteral{ElemType: TypeInfo}
+	fieldList := &types.ListLiteral{ElemType: TypeField}
+	TypeInfo.Members = []types.StructField{
+		{Name: "name", Type: stringType, Exported: true},
+		{Name: "kind", Type: stringType, Exported: true},
+		// Struct fields, in declaration order
+		{Name: "fields", Type: fieldList, Exported: true},
+		// The members of a union or enum, or the types that make up any other compound type
+		{Name: "members", Type: typeList, Exported: true},
+		{Name: "params", Type: fieldList, Exported: true},
+		{Name: "returns", Type: types.MakeOptional(TypeInfo), Exported: true},
+	}
+}
+
 func registerBuiltin(name string, parameters params, returnType types.ValidType) {
+	registerBuiltinWithOptional(name, parameters, params{}, returnType)
+}
+
+// Registers a builtin whose trailing optional parameters can be left out when calling it
+func registerBuiltinWithOptional(name string, parameters, optional params, returnType types.ValidType) {
 	data := builtin{
-		Parameters: parameters,
+		Parameters: append(parameters, optional...),
+		Optional:   len(optional),
 		ReturnType: returnType,
 	}
 
 	Builtins[name] = data
 }
 
+// Registers a builtin which can be passed any number of arguments of the rest type after its parameters
+func registerVariadicBuiltin(name string, parameters params, rest types.ValidType, returnType types.ValidType) {
+	data := builtin{
+		Parameters: append(parameters, &types.ListLiteral{ElemType: rest}),
+		Variadic:   true,
+		ReturnType: returnType,
+	}
+
+	Builtins[name] = data
+}
+
+// Registers a builtin whose return type depends on the types of its arguments
+func registerGenericBuiltin(name string, parameters params, infer func([]types.ValidType) (types.ValidType, string)) {
+	Builtins[name] = builtin{
+		Parameters: parameters,
+		Infer:      infer,
+	}
+}
+
 func err(ty types.ValidType) types.ValidType {
 	return &types.ErrorType{ResultType: ty}
 }
 
 func registerBuiltins() {
-	registerBuiltin("print", params{&types.Any{}}, &types.Void{})
-	registerBuiltin("printil", params{&types.Any{}}, &types.Void{})
-	registerBuiltin("prompt", params{stringType}, stringType)
+	registerVariadicBuiltin("print", params{}, &types.Any{}, &types.Void{})
+	registerVariadicBuiltin("printil", params{}, &types.Any{}, &types.Void{})
+	registerBuiltinWithOptional("prompt", params{}, params{stringType}, err(stringType))
 	registerBuiltin("to_string", params{&types.Any{}}, stringType)
 	registerBuiltin("parse_int", params{stringType}, err(intType))
 	registerBuiltin("parse_float", params{stringType}, err(floatType))
 	registerBuiltin("read_file", params{stringType}, err(stringType))
 	registerBuiltin("write_file", params{stringType, stringType}, err(&types.Void{}))
+	registerBuiltin("get_env", params{stringType}, err(stringType))
+	registerBuiltin("wrap_error", params{types.ErrorInterface, stringType}, types.ErrorInterface)
+	registerBuiltin("unwrap_error", params{types.ErrorInterface}, types.MakeOptional(types.ErrorInterface))
+	registerBuiltinWithOptional("run_command", params{stringType}, params{&types.ListLiteral{ElemType: stringType}}, err(stringType))
+
+	registerTypeInfo()
+	registerBuiltin("typeof", params{&types.Any{}}, TypeInfo)
+
+	// A list of any type, whose element type is inferred from the argument
+	anyList := &types.ListLiteral{ElemType: &types.Infer{}}
+	registerGenericBuiltin("map", params{anyList, &types.Any{}}, inferMap)
+	registerGenericBuiltin("filter", params{anyList, &types.Any{}}, inferPredicate(false))
+	registerGenericBuiltin("any", params{anyList, &types.Any{}}, inferPredicate(true))
+	registerGenericBuiltin("all", params{anyList, &types.Any{}}, inferPredicate(true))
+	registerGenericBuiltin("reduce", params{anyList, &types.Any{}, &types.Any{}}, inferReduce)
+	registerGenericBuiltin("sort_by", params{anyList, &types.Any{}}, inferSortBy)
+}
+
+// Checks that a function can be called with arguments of the given types
+func callback(dataType types.ValidType, args ...types.ValidType) (*types.Function, string) {
+	fn, isFn := dataType.(*types.Function)
+	if !isFn {
+		return nil, fmt.Sprintf("Type %q is not a function", dataType)
+	}
+
+	required := len(fn.Parameters) - fn.Defaults
+	if fn.Variadic || len(args) < required || len(args) > len(fn.Parameters) {
+		if len(args) == 1 {
+			return nil, fmt.Sprintf("Function %q must take 1 parameter", fn.Name)
+		}
+		return nil, fmt.Sprintf("Function %q must take %d parameters", fn.Name, len(args))
+	}
+
+	for i, arg := range args {
+		if !fn.Parameters[i].Valid(arg) {
+			return nil, fmt.Sprintf("Function %q cannot be called with an argument of type %q", fn.Name, arg)
+		}
+	}
+	return fn, ""
+}
+
+// The element type of a list argument, once ListLiteral.Infer has worked it out
+func listElemType(list types.ValidType) types.ValidType {
+	elemType, _ := types.ElemTypeOf(list)
+	return elemType
+}
+
+func inferMap(args []types.ValidType) (types.ValidType, string) {
+	elemType := listElemType(args[0])
+
+	fn, message := callback(args[1], elemType)
+	if message != "" {
+		return nil, message
+	}
+	if _, isVoid := fn.ReturnType.(*types.Void); isVoid {
+		return nil, fmt.Sprintf("Function %q must return a value", fn.Name)
+	}
+	return &types.ListLiteral{ElemType: fn.ReturnType}, ""
+}
+
+// Builtins which test each element, either giving the elements which pass or whether they pass
+func inferPredicate(returnsBool bool) func([]types.ValidType) (types.ValidType, string) {
+	return func(args []types.ValidType) (types.ValidType, string) {
+		elemType := listElemType(args[0])
+
+		fn, message := callback(args[1], elemType)
+		if message != "" {
+			return nil, message
+		}
+		if !boolType.Valid(fn.ReturnType) {
+			return nil, fmt.Sprintf("Function %q must return a boolean", fn.Name)
+		}
+
+		if returnsBool {
+			return boolType, ""
+		}
+		return &types.ListLiteral{ElemType: elemType}, ""
+	}
+}
+
+func inferReduce(args []types.ValidType) (types.ValidType, string) {
+	elemType := listElemType(args[0])
+
+	fn, message := callback(args[1], args[2], elemType)
+	if message != "" {
+		return nil, message
+	}
+	if !fn.Parameters[0].Valid(fn.ReturnType) {
+		return nil, fmt.Sprintf("Function %q must return its first parameter's type", fn.Name)
+	}
+	return fn.Parameters[0], ""
+}
+
+func inferSortBy(args []types.ValidType) (types.ValidType, string) {
+	elemType := listElemType(args[0])
+
+	fn, message := callback(args[1], elemType)
+	if message != "" {
+		return nil, message
+	}
+	if !intType.Valid(fn.ReturnType) && !floatType.Valid(fn.ReturnType) && !stringType.Valid(fn.ReturnType) {
+		return nil, fmt.Sprintf("Function %q must return an int, float or string to sort by", fn.Name)
+	}
+	return &types.ListLiteral{ElemType: elemType}, ""
 }
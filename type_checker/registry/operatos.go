@@ -12,10 +12,17 @@ type unaryOperatorChecker func(types.ValidType, bool) types.ValidType
 var BinaryOperators = map[string]binaryOperatorChecker{}
 var UnaryOperators = map[string]unaryOperatorChecker{}
 
+// The names of methods user types can define to overload binary operators
+var OperatorMethods = map[string]string{}
+
 func registerBinaryOperator(operator string, fn binaryOperatorChecker) {
 	BinaryOperators[operator] = fn
 }
 
+func registerOperatorMethod(operator string, method string) {
+	OperatorMethods[operator] = method
+}
+
 func registerUnaryOperator(operator string, fn unaryOperatorChecker) {
 	UnaryOperators[operator] = fn
 }
@@ -177,11 +184,17 @@ func notOperator(dataType types.ValidType, postfix bool) types.ValidType {
 	if errType, ok := dataType.(*types.ErrorType); ok {
 		return errType.ResultType
 	}
+	if optional, ok := dataType.(*types.Optional); ok {
+		return optional.DataType
+	}
 	return nil
 }
 
 func referenceOperator(dataType types.ValidType, _ bool) types.ValidType {
-	return &types.Pointer{DataType: dataType}
+	if pseudo, ok := dataType.(types.PseudoType); ok {
+		dataType = pseudo.ToReal()
+	}
+	return &types.Pointer{DataType: dataType, Const: dataType.Constant()}
 }
 
 func dereferenceOperator(dataType types.ValidType, _ bool) types.ValidType {
@@ -192,6 +205,43 @@ func dereferenceOperator(dataType types.ValidType, _ bool) types.ValidType {
 	return ptr.DataType
 }
 
+func coalesceOperator(leftType, rightType types.ValidType) types.ValidType {
+	optional, isOptional := leftType.(*types.Optional)
+	if !isOptional {
+		return types.Error(fmt.Sprintf("Operator \"??\" can only be used on optional values, not type %q", leftType))
+	}
+
+	if optional.DataType.Valid(rightType) {
+		return optional.DataType
+	}
+	// The default value can be optional too, in which case so is the result
+	if optional.Valid(rightType) {
+		return optional
+	}
+
+	return types.Error(fmt.Sprintf("Type %q cannot be used as a default for type %q", rightType, leftType))
+}
+
+func sendOperator(leftType, rightType types.ValidType) types.ValidType {
+	channel, isChannel := leftType.(*types.Channel)
+	if !isChannel {
+		return nil
+	}
+
+	if !channel.ElemType.Valid(rightType) {
+		return types.Error(fmt.Sprintf("Cannot send value of type %q on channel of type %q", rightType, leftType))
+	}
+	return &types.Void{}
+}
+
+func receiveOperator(dataType types.ValidType, _ bool) types.ValidType {
+	channel, ok := dataType.(*types.Channel)
+	if !ok {
+		return nil
+	}
+	return channel.ElemType
+}
+
 func registerOperators() {
 	registerBinaryOperator("+", plusOperator)
 	registerBinaryOperator("-", arithmeticOperator)
@@ -213,6 +263,22 @@ func registerOperators() {
 	registerBinaryOperator("||", logicalOperator)
 	registerBinaryOperator("&&", logicalOperator)
 
+	registerBinaryOperator("??", coalesceOperator)
+	registerBinaryOperator("<-", sendOperator)
+
+	registerOperatorMethod("+", "add")
+	registerOperatorMethod("-", "sub")
+	registerOperatorMethod("*", "mul")
+	registerOperatorMethod("/", "div")
+	registerOperatorMethod("%", "mod")
+	registerOperatorMethod("**", "pow")
+	registerOperatorMethod("==", "eq")
+	registerOperatorMethod("!=", "eq")
+	registerOperatorMethod("<", "cmp")
+	registerOperatorMethod(">", "cmp")
+	registerOperatorMethod("<=", "cmp")
+	registerOperatorMethod(">=", "cmp")
+
 	registerUnaryOperator("++", func(v types.ValidType, _ bool) types.ValidType { return incDecOperator(v, "++") })
 	registerUnaryOperator("--", func(v types.ValidType, _ bool) types.ValidType { return incDecOperator(v, "--") })
 	registerUnaryOperator("!", notOperator)
@@ -220,4 +286,5 @@ func registerOperators() {
 	registerUnaryOperator("-", negateOperator)
 	registerUnaryOperator("&", referenceOperator)
 	registerUnaryOperator("*", dereferenceOperator)
+	registerUnaryOperator("<-", receiveOperator)
 }
@@ -12,4 +12,5 @@ var stringType = &types.StringLiteral{}
 func Register() {
 	registerOperators()
 	registerBuiltins()
+	registerStdModules()
 }
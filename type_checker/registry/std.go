@@ -0,0 +1,35 @@
+package registry
+
+import "github.com/gearsdatapacks/libra/type_checker/types"
+
+// The exports of the modules built into the interpreter, imported with "std:name"
+var StdModules = map[string]map[string]types.ValidType{}
+
+func registerStdModules() {
+	StdModules["json"] = map[string]types.ValidType{
+		"encode": &types.Function{
+			Name:           "encode",
+			Parameters:     params{&types.Any{}},
+			ParameterNames: []string{"value"},
+			ReturnType:     err(stringType),
+			Exported:       true,
+		},
+		"decode": &types.Function{
+			Name:           "decode",
+			Parameters:     params{stringType},
+			ParameterNames: []string{"text"},
+			ReturnType:     err(&types.Any{}),
+			Exported:       true,
+			// The type argument is the type the JSON is decoded into
+			Instantiate: func(typeArgument types.ValidType) *types.Function {
+				return &types.Function{
+					Name:           "decode",
+					Parameters:     params{stringType},
+					ParameterNames: []string{"text"},
+					ReturnType:     err(typeArgument),
+					Exported:       true,
+				}
+			},
+		},
+	}
+}
@@ -3,6 +3,8 @@ package typechecker
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/gearsdatapacks/libra/errors"
 	"github.com/gearsdatapacks/libra/modules"
@@ -26,6 +28,9 @@ func typeCheckStatement(stmt ast.Statement, manager *modules.ModuleManager) type
 	case *ast.ReturnStatement:
 		dataType = typeCheckReturnStatement(statement, manager)
 
+	case *ast.DeferStatement:
+		dataType = typeCheckDeferStatement(statement, manager)
+
 	case *ast.IfStatement:
 		dataType = typeCheckIfStatement(statement, manager)
 
@@ -35,6 +40,9 @@ func typeCheckStatement(stmt ast.Statement, manager *modules.ModuleManager) type
 	case *ast.ForLoop:
 		dataType = typeCheckForLoop(statement, manager)
 
+	case *ast.SelectStatement:
+		dataType = typeCheckSelectStatement(statement, manager)
+
 	case *ast.StructDeclaration:
 		// return typeCheckStructDeclaration(statement, manager)
 		return &types.Void{}
@@ -47,6 +55,9 @@ func typeCheckStatement(stmt ast.Statement, manager *modules.ModuleManager) type
 		// return typeCheckInterfaceDeclaration(statement, manager)
 		return &types.Void{}
 
+	case *ast.ImplDeclaration:
+		dataType = typeCheckImplDeclaration(statement, manager)
+
 	case *ast.TypeDeclaration:
 		// return typeCheckTypeDeclataion(statement, manager)
 		return &types.Void{}
@@ -135,6 +146,10 @@ func typeCheckVariableDeclaration(varDec *ast.VariableDeclaration, manager *modu
 	}
 
 	if varDec.Value == nil {
+		if !types.HasZeroValue(dataType) {
+			return types.Error(fmt.Sprintf("Variable %q must be given a value, as type %q has no zero value", varDec.Name, dataType), varDec)
+		}
+
 		err := manager.SymbolTable.RegisterSymbol(varDec.Name, dataType, varDec.Constant)
 		if err != nil {
 			err.Line = varDec.Token.Line
@@ -150,17 +165,15 @@ func typeCheckVariableDeclaration(varDec *ast.VariableDeclaration, manager *modu
 	}
 
 	if _, ok := expressionType.(*types.Void); ok {
-		return types.Error(fmt.Sprintf("Cannot assign void to variable %q", varDec.Name), varDec)
+		name := varDec.Name
+		if varDec.Pattern != nil {
+			name = varDec.Pattern.String()
+		}
+		return types.Error(fmt.Sprintf("Cannot assign void to variable %q", name), varDec)
 	}
 
 	if dataType.String() == "Infer" {
-		err := manager.SymbolTable.RegisterSymbol(varDec.Name, expressionType, varDec.Constant)
-		if err != nil {
-			err.Line = varDec.Token.Line
-			err.Column = varDec.Token.Column
-			return err
-		}
-		return expressionType
+		return declareVariable(varDec, expressionType, manager)
 	}
 
 	correctType := dataType.Valid(expressionType)
@@ -170,31 +183,63 @@ func typeCheckVariableDeclaration(varDec *ast.VariableDeclaration, manager *modu
 	}
 
 	if correctType {
-		err := manager.SymbolTable.RegisterSymbol(varDec.Name, dataType, varDec.Constant)
-		if err != nil {
-			err.Line = varDec.Token.Line
-			err.Column = varDec.Token.Column
-			return err
-		}
-		return dataType
+		return declareVariable(varDec, dataType, manager)
 	}
 
 	return types.Error(fmt.Sprintf("Type %q is not assignable to type %q", expressionType, dataType), varDec)
 }
 
+func declareVariable(varDec *ast.VariableDeclaration, dataType types.ValidType, manager *modules.ModuleManager) types.ValidType {
+	if varDec.Pattern != nil {
+		return typeCheckPattern(varDec.Pattern, dataType, varDec.Constant, manager)
+	}
+
+	err := manager.SymbolTable.RegisterSymbol(varDec.Name, dataType, varDec.Constant)
+	if err != nil {
+		err.Line = varDec.Token.Line
+		err.Column = varDec.Token.Column
+		return err
+	}
+	return dataType
+}
+
 func typeCheckFunctionDeclaration(funcDec *ast.FunctionDeclaration, manager *modules.ModuleManager) types.ValidType {
 	var fn *types.Function
 	if funcDec.MethodOf == nil {
 		fn = manager.SymbolTable.GetSymbol(funcDec.Name).(*types.Function)
+	} else if funcDec.Associated {
+		parentType := TypeCheckType(funcDec.MethodOf, manager)
+		fn = types.AssociatedFunction(parentType, funcDec.Name, manager.Id)
 	} else {
 		parentType := TypeCheckType(funcDec.MethodOf, manager)
-		fn = types.Member(parentType, funcDec.Name, false, manager.Id).(*types.Function)
+		fn = types.DeclaredMember(parentType, funcDec.Name, manager.Id).(*types.Function)
+	}
+
+	// Methods are all registered by now, so we can check the declared errors really are errors
+	if errorType, ok := fn.ReturnType.(*types.ErrorType); ok {
+		for _, declaredError := range errorType.ErrorTypes {
+			if !types.ErrorInterface.Valid(declaredError) {
+				return types.Error(fmt.Sprintf("Type %q cannot be returned as an error, it does not have an error() method", declaredError), funcDec.ReturnType)
+			}
+		}
 	}
 
 	childTable := symbols.NewFunction(manager.SymbolTable, fn.ReturnType)
 	manager.EnterScope(childTable)
 	for i, param := range funcDec.Parameters {
 		paramType := fn.Parameters[i]
+
+		// Defaults are evaluated when the function is called, so they can use earlier parameters
+		if param.Default != nil {
+			defaultType := typeCheckExpression(param.Default, manager)
+			if defaultType.String() == "TypeError" {
+				return defaultType
+			}
+			if !paramType.Valid(defaultType) {
+				return types.Error(fmt.Sprintf("Default value of type %q is not valid for parameter %q of type %q", defaultType, param.Name, paramType), param.Default)
+			}
+		}
+
 		err := childTable.RegisterSymbol(param.Name, paramType, false)
 		if err != nil {
 			err.Line = funcDec.Token.Line
@@ -203,7 +248,9 @@ func typeCheckFunctionDeclaration(funcDec *ast.FunctionDeclaration, manager *mod
 		}
 	}
 
-	if fn.MethodOf != nil {
+	if fn.PointerReceiver {
+		childTable.RegisterSymbol("this", &types.Pointer{DataType: fn.MethodOf}, true)
+	} else if fn.MethodOf != nil && !funcDec.Associated {
 		childTable.RegisterSymbol("this", fn.MethodOf, true)
 	}
 
@@ -234,6 +281,8 @@ func typeCheckFunctionParams(funcDec *ast.FunctionDeclaration, manager *modules.
 			ReturnType: &types.Void{},
 			MethodOf:   nil,
 			Exported:   funcDec.Exported,
+
+			PointerReceiver: funcDec.PointerReceiver,
 		}
 	}
 
@@ -242,7 +291,15 @@ func typeCheckFunctionParams(funcDec *ast.FunctionDeclaration, manager *modules.
 		if paramType.String() == "TypeError" {
 			return paramType
 		}
+		if param.Variadic {
+			paramType = &types.ListLiteral{ElemType: paramType}
+			fnType.Variadic = true
+		}
 		fnType.Parameters = append(fnType.Parameters, paramType)
+		fnType.ParameterNames = append(fnType.ParameterNames, param.Name)
+		if param.Default != nil {
+			fnType.Defaults++
+		}
 	}
 
 	returnType := TypeCheckType(funcDec.ReturnType, manager)
@@ -257,12 +314,25 @@ func typeCheckFunctionParams(funcDec *ast.FunctionDeclaration, manager *modules.
 			return parentType
 		}
 
-		if types.Member(parentType, funcDec.Name, false, manager.Id) != nil {
+		fnType.MethodOf = parentType
+
+		if funcDec.Associated {
+			if types.AssociatedFunction(parentType, funcDec.Name, manager.Id) != nil {
+				return types.Error(fmt.Sprintf("Type %q already has associated function %q", parentType.String(), funcDec.Name), funcDec)
+			}
+			types.AddAssociatedFunction(funcDec.Name, fnType)
+			return fnType
+		}
+
+		if types.DeclaredMember(parentType, funcDec.Name, manager.Id) != nil {
 			return types.Error(fmt.Sprintf("Type %q already has member %q", parentType.String(), funcDec.Name), funcDec)
 		}
-		fnType.MethodOf = parentType
 
-		types.AddMethod(funcDec.Name, fnType)
+		if err := types.AddMethod(funcDec.Name, fnType); err != nil {
+			err.Line = funcDec.Token.Line
+			err.Column = funcDec.Token.Column
+			return err
+		}
 	}
 
 	return fnType
@@ -285,7 +355,7 @@ func registerFunctionDeclaration(funcDec *ast.FunctionDeclaration, manager *modu
 		}
 	}
 
-	if funcDec.IsExport() {
+	if funcDec.IsExport() && !funcDec.Associated {
 		manager.SymbolTable.GlobalScope().AddExport(funcDec.Name, functionType, manager.Id)
 	}
 
@@ -313,54 +383,122 @@ func typeCheckReturnStatement(ret *ast.ReturnStatement, manager *modules.ModuleM
 	return expressionType
 }
 
+func typeCheckDeferStatement(deferStmt *ast.DeferStatement, manager *modules.ModuleManager) types.ValidType {
+	if manager.SymbolTable.FindFunctionScope() == nil {
+		return types.Error("Cannot use defer statement outside of a function", deferStmt)
+	}
+
+	callType := typeCheckExpression(deferStmt.Call, manager)
+	if callType.String() == "TypeError" {
+		return callType
+	}
+	return &types.Void{}
+}
+
 func typeCheckIfStatement(ifStatement *ast.IfStatement, manager *modules.ModuleManager) types.ValidType {
+	_, err := typeCheckIfBranches(ifStatement, manager)
+	if err != nil {
+		return err
+	}
+	return &types.Void{}
+}
+
+// Type checks an if statement, returning the types of the values its branches give.
+// A missing else gives null, and branches which always return are left out.
+func typeCheckIfBranches(ifStatement *ast.IfStatement, manager *modules.ModuleManager) ([]types.ValidType, *types.TypeError) {
 	err := typeCheckExpression(ifStatement.Condition, manager)
 	if err.String() == "TypeError" {
-		return err
+		return nil, err.(*types.TypeError)
 	}
+	whenTrue, whenFalse := narrowCondition(ifStatement.Condition, manager)
+	branches := []types.ValidType{}
 
 	newScope := symbols.NewChild(manager.SymbolTable, symbols.CONDITIONAL_SCOPE)
 	manager.EnterScope(newScope)
+	applyNarrowing(whenTrue, manager)
 
-	for _, statement := range ifStatement.Body {
-		err := typeCheckStatement(statement, manager)
-		if err.String() == "TypeError" {
-			return err
-		}
+	bodyType := typeCheckBlock(ifStatement.Body, manager)
+	if bodyType.String() == "TypeError" {
+		return nil, bodyType.(*types.TypeError)
+	}
+	if !alwaysReturns(ifStatement.Body) {
+		branches = append(branches, bodyType)
 	}
 	manager.ExitScope()
 
-	if nextIf, isIf := ifStatement.Else.(*ast.IfStatement); isIf {
-		return typeCheckIfStatement(nextIf, manager)
+	if ifStatement.Else == nil {
+		branches = append(branches, &types.NullLiteral{})
+	} else {
+		enterNarrowedScope(whenFalse, manager)
+		if nextIf, isIf := ifStatement.Else.(*ast.IfStatement); isIf {
+			elseBranches, err := typeCheckIfBranches(nextIf, manager)
+			if err != nil {
+				return nil, err
+			}
+			branches = append(branches, elseBranches...)
+		} else if nextElse, isElse := ifStatement.Else.(*ast.ElseStatement); isElse {
+			elseType := typeCheckElseStatement(nextElse, manager)
+			if elseType.String() == "TypeError" {
+				return nil, elseType.(*types.TypeError)
+			}
+			if !alwaysReturns(nextElse.Body) {
+				branches = append(branches, elseType)
+			}
+		}
+		manager.ExitScope()
 	}
 
-	if nextElse, isElse := ifStatement.Else.(*ast.ElseStatement); isElse {
-		return typeCheckElseStatement(nextElse, manager)
+	// If one branch always returns, the rest of the block can only run if the other was taken
+	if alwaysReturns(ifStatement.Body) {
+		applyNarrowing(whenFalse, manager)
+	} else if elseStatement, isElse := ifStatement.Else.(*ast.ElseStatement); isElse && alwaysReturns(elseStatement.Body) {
+		applyNarrowing(whenTrue, manager)
 	}
 
-	return &types.Void{}
+	return branches, nil
 }
 
 func typeCheckElseStatement(elseStatement *ast.ElseStatement, manager *modules.ModuleManager) types.ValidType {
 	newScope := symbols.NewChild(manager.SymbolTable, symbols.FALLBACK_SCOPE)
 	manager.EnterScope(newScope)
 
-	for _, statement := range elseStatement.Body {
-		err := typeCheckStatement(statement, manager)
-		if err.String() == "TypeError" {
-			return err
-		}
+	dataType := typeCheckBlock(elseStatement.Body, manager)
+	if dataType.String() == "TypeError" {
+		return dataType
 	}
 	manager.ExitScope()
 
-	return &types.Void{}
+	return dataType
+}
+
+// Type checks a block of code, returning the type of its final expression, if any
+func typeCheckBlock(body []ast.Statement, manager *modules.ModuleManager) types.ValidType {
+	var dataType types.ValidType = &types.Void{}
+
+	for _, statement := range body {
+		dataType = typeCheckStatement(statement, manager)
+		if dataType.String() == "TypeError" {
+			return dataType
+		}
+	}
+
+	if len(body) == 0 {
+		return &types.Void{}
+	}
+	if _, isExpression := body[len(body)-1].(*ast.ExpressionStatement); !isExpression {
+		return &types.Void{}
+	}
+	return dataType
 }
 
 func typeCheckWhileLoop(while *ast.WhileLoop, manager *modules.ModuleManager) types.ValidType {
+	forgetAssigned(while, manager)
 	typeCheckExpression(while.Condition, manager)
+	whenTrue, _ := narrowCondition(while.Condition, manager)
 
 	newScope := symbols.NewChild(manager.SymbolTable, symbols.CONDITIONAL_SCOPE)
 	manager.EnterScope(newScope)
+	applyNarrowing(whenTrue, manager)
 
 	for _, statement := range while.Body {
 		err := typeCheckStatement(statement, manager)
@@ -368,11 +506,13 @@ func typeCheckWhileLoop(while *ast.WhileLoop, manager *modules.ModuleManager) ty
 			return err
 		}
 	}
+	manager.ExitScope()
 
 	return &types.Void{}
 }
 
 func typeCheckForLoop(forLoop *ast.ForLoop, manager *modules.ModuleManager) types.ValidType {
+	forgetAssigned(forLoop, manager)
 	newScope := symbols.NewChild(manager.SymbolTable, symbols.CONDITIONAL_SCOPE)
 	manager.EnterScope(newScope)
 	err := typeCheckStatement(forLoop.Initial, manager)
@@ -402,13 +542,17 @@ func typeCheckForLoop(forLoop *ast.ForLoop, manager *modules.ModuleManager) type
 func typeCheckStructDeclaration(structDecl *ast.StructDeclaration, manager *modules.ModuleManager) types.ValidType {
 	structType := manager.SymbolTable.GetType(structDecl.Name).(*types.Struct)
 
-	for memberName, field := range structDecl.Members {
+	for _, field := range structDecl.Members {
+		if _, exists := structType.Field(field.Name); exists {
+			return types.Error(fmt.Sprintf("Struct %q already has a field named %q", structDecl.Name, field.Name), structDecl)
+		}
+
 		dataType := TypeCheckType(field.Type, manager)
 		if dataType.String() == "TypeError" {
 			return dataType
 		}
 
-		structType.Members[memberName] = types.StructField{Type: dataType, Exported: field.Exported}
+		structType.Members = append(structType.Members, types.StructField{Name: field.Name, Type: dataType, Exported: field.Exported})
 	}
 
 	return structType
@@ -432,6 +576,22 @@ func typeCheckTupleStructDeclaration(structDecl *ast.TupleStructDeclaration, man
 func typeCheckInterfaceDeclaration(intDecl *ast.InterfaceDeclaration, manager *modules.ModuleManager) types.ValidType {
 	interfaceType := manager.SymbolTable.GetType(intDecl.Name).(*types.Interface)
 
+	for _, embed := range intDecl.Embeds {
+		embedType := TypeCheckType(embed, manager)
+		if embedType.String() == "TypeError" {
+			return embedType
+		}
+
+		embedded, isInterface := embedType.(*types.Interface)
+		if !isInterface {
+			return types.Error(fmt.Sprintf("Interface %q can only embed other interfaces, not %q", intDecl.Name, embedType), embed)
+		}
+		if embedded == interfaceType || embedded.EmbedsInterface(interfaceType) {
+			return types.Error(fmt.Sprintf("Interface %q cannot embed itself", intDecl.Name), embed)
+		}
+		interfaceType.Embeds = append(interfaceType.Embeds, embedded)
+	}
+
 	for _, member := range intDecl.Members {
 		if !member.IsFunction {
 			dataType := TypeCheckType(member.ResultType, manager)
@@ -454,12 +614,17 @@ func typeCheckInterfaceDeclaration(intDecl *ast.InterfaceDeclaration, manager *m
 
 		fnType.Parameters = []types.ValidType{}
 		for _, param := range member.Parameters {
-			paramType := TypeCheckType(param, manager)
+			paramType := TypeCheckType(param.Type, manager)
 			if paramType.String() == "TypeError" {
 				return paramType
 			}
+			if param.Variadic {
+				paramType = &types.ListLiteral{ElemType: paramType}
+				fnType.Variadic = true
+			}
 
 			fnType.Parameters = append(fnType.Parameters, paramType)
+			fnType.ParameterNames = append(fnType.ParameterNames, param.Name)
 		}
 
 		interfaceType.Members[member.Name] = fnType
@@ -468,6 +633,122 @@ func typeCheckInterfaceDeclaration(intDecl *ast.InterfaceDeclaration, manager *m
 	return interfaceType
 }
 
+// Checks a type implements every member of an interface, giving it the interface's default methods it doesn't define itself
+func typeCheckImplParams(impl *ast.ImplDeclaration, manager *modules.ModuleManager) types.ValidType {
+	interfaceType := TypeCheckType(impl.Interface, manager)
+	if interfaceType.String() == "TypeError" {
+		return interfaceType
+	}
+	iface, isInterface := interfaceType.(*types.Interface)
+	if !isInterface {
+		return types.Error(fmt.Sprintf("Cannot implement %q, it is not an interface", interfaceType), impl.Interface)
+	}
+
+	implType := TypeCheckType(impl.For, manager)
+	if implType.String() == "TypeError" {
+		return implType
+	}
+
+	members := iface.AllMembers()
+	names := []string{}
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []string{}
+	for _, name := range names {
+		expected := members[name]
+		actual := types.Member(implType, name, false, manager.Id)
+
+		if actual == nil {
+			defaultMethod := findDefaultMethod(iface, name)
+			if defaultMethod == nil {
+				problems = append(problems, fmt.Sprintf("missing %s %q", memberKind(expected), name))
+				continue
+			}
+
+			// Each type gets its own copy of the body, as it's annotated differently depending on the type
+			method := &ast.FunctionDeclaration{
+				Name:       name,
+				MethodOf:   impl.For,
+				Parameters: ast.Copy(defaultMethod.Parameters),
+				ReturnType: ast.Copy(defaultMethod.ResultType),
+				Body:       ast.Copy(defaultMethod.Body),
+				BaseNode:   ast.BaseNode{Token: impl.Token},
+			}
+			fnType := typeCheckFunctionParams(method, manager)
+			if fnType.String() == "TypeError" {
+				return fnType
+			}
+			method.SetType(fnType)
+			impl.Methods = append(impl.Methods, method)
+			continue
+		}
+
+		if !expected.Valid(actual) {
+			problems = append(problems, fmt.Sprintf("%s %q should be %s, not %s", memberKind(expected), name, describeMember(expected), describeMember(actual)))
+		}
+	}
+
+	if len(problems) != 0 {
+		return types.Error(fmt.Sprintf("Type %q does not implement interface %q: %s", implType, iface, strings.Join(problems, ", ")), impl)
+	}
+
+	return iface
+}
+
+func typeCheckImplDeclaration(impl *ast.ImplDeclaration, manager *modules.ModuleManager) types.ValidType {
+	for _, method := range impl.Methods {
+		result := typeCheckFunctionDeclaration(method, manager)
+		if result.String() == "TypeError" {
+			return result
+		}
+	}
+	return &types.Void{}
+}
+
+// The interfaces declared in the program, so their default methods can be found
+var interfaceDeclarations = map[*types.Interface]*ast.InterfaceDeclaration{}
+
+func findDefaultMethod(iface *types.Interface, name string) *ast.InterfaceMember {
+	if intDecl, ok := interfaceDeclarations[iface]; ok {
+		for i, member := range intDecl.Members {
+			if member.Name == name && member.Body != nil {
+				return &intDecl.Members[i]
+			}
+		}
+	}
+
+	for _, embedded := range iface.Embeds {
+		if method := findDefaultMethod(embedded, name); method != nil {
+			return method
+		}
+	}
+	return nil
+}
+
+func memberKind(member types.ValidType) string {
+	if _, isFunction := member.(*types.Function); isFunction {
+		return "method"
+	}
+	return "field"
+}
+
+// Describes the type of an interface member, including the signature of methods
+func describeMember(member types.ValidType) string {
+	fn, isFunction := member.(*types.Function)
+	if !isFunction {
+		return fmt.Sprintf("%q", member)
+	}
+
+	params := []string{}
+	for _, param := range fn.Parameters {
+		params = append(params, param.String())
+	}
+	return fmt.Sprintf("\"fn(%s): %s\"", strings.Join(params, ", "), fn.ReturnType)
+}
+
 func typeCheckTypeDeclataion(typeDecl *ast.TypeDeclaration, manager *modules.ModuleManager) types.ValidType {
 	dataType := TypeCheckType(typeDecl.DataType, manager)
 	if dataType.String() == "TypeError" {
@@ -486,11 +767,9 @@ func typeCheckTypeDeclataion(typeDecl *ast.TypeDeclaration, manager *modules.Mod
 }
 
 func registerStructDeclaration(structDecl *ast.StructDeclaration, manager *modules.ModuleManager) types.ValidType {
-	members := map[string]types.StructField{}
-
 	structType := &types.Struct{
 		Name:    structDecl.Name,
-		Members: members,
+		Members: []types.StructField{},
 	}
 
 	err := manager.SymbolTable.AddType(structDecl.Name, structType)
@@ -551,6 +830,7 @@ func registerInterfaceDeclaration(intDecl *ast.InterfaceDeclaration, manager *mo
 	if err != nil {
 		return err
 	}
+	interfaceDeclarations[interfaceType] = intDecl
 
 	if intDecl.IsExport() {
 		manager.SymbolTable.AddExport(intDecl.Name, &types.Type{DataType: interfaceType}, manager.Id)
@@ -631,7 +911,8 @@ func registerEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Modu
 		}
 	}
 
-	for name, member := range enumDec.Members {
+	for _, member := range enumDec.Members {
+		name := member.Name
 		if enumDec.IsUnion {
 			memberType := &types.Type{DataType: &types.Void{}}
 			err := manager.SymbolTable.AddType(name, memberType)
@@ -645,10 +926,15 @@ func registerEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Modu
 
 			dataType.(*types.Union).Types = append(dataType.(*types.Union).Types, memberType)
 		} else {
-			dataType.(*types.Enum).Types[name] = &types.EnumMember{
+			enum := dataType.(*types.Enum)
+			if _, exists := enum.Types[name]; exists {
+				return types.Error(fmt.Sprintf("Enum %q already has a member named %q", enumDec.Name, name), enumDec)
+			}
+			enum.Types[name] = &types.EnumMember{
 				DataType: &types.Type{DataType: &types.Void{}},
 				Exported: member.Exported,
 			}
+			enum.Order = append(enum.Order, name)
 		}
 	}
 
@@ -661,6 +947,26 @@ func registerEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Modu
 		if err != nil {
 			return err
 		}
+
+		enum := dataType.(*types.Enum)
+		if err := setDiscriminants(enumDec, enum); err != nil {
+			return err
+		}
+		types.AddAssociatedFunction("values", &types.Function{
+			Name:       "values",
+			Parameters: []types.ValidType{},
+			ReturnType: &types.ListLiteral{ElemType: enum},
+			MethodOf:   enum,
+			Exported:   true,
+		})
+		types.AddAssociatedFunction("from", &types.Function{
+			Name:           "from",
+			Parameters:     []types.ValidType{&types.StringLiteral{}},
+			ParameterNames: []string{"name"},
+			ReturnType:     &types.ErrorType{ResultType: enum},
+			MethodOf:       enum,
+			Exported:       true,
+		})
 	}
 
 	if enumDec.IsExport() {
@@ -670,10 +976,67 @@ func registerEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Modu
 	return &types.Void{}
 }
 
+// Gives each member without data the value it converts to and from with ->.
+// Int discriminants count up from the previous one, and string ones default to the member's name
+func setDiscriminants(enumDec *ast.EnumDeclaration, enum *types.Enum) *types.TypeError {
+	enum.DiscriminantType = &types.IntLiteral{}
+	for _, member := range enumDec.Members {
+		if _, isString := member.Discriminant.(*ast.StringLiteral); isString {
+			enum.DiscriminantType = &types.StringLiteral{}
+		}
+	}
+	_, isString := enum.DiscriminantType.(*types.StringLiteral)
+
+	next := 0
+	seen := map[any]string{}
+	for _, member := range enumDec.Members {
+		if member.Types != nil || member.StructMembers != nil {
+			continue
+		}
+
+		var discriminant any
+		switch value := member.Discriminant.(type) {
+		case nil:
+			if isString {
+				discriminant = member.Name
+			} else {
+				discriminant = next
+			}
+		case *ast.IntegerLiteral:
+			discriminant = value.Value
+		case *ast.StringLiteral:
+			discriminant = value.Value
+		case *ast.UnaryOperation:
+			if integer, ok := value.Value.(*ast.IntegerLiteral); ok && value.Operator == "-" {
+				discriminant = -integer.Value
+			}
+		}
+
+		if discriminant == nil {
+			return types.Error("Enum discriminants must be int or string literals", member.Discriminant)
+		}
+		if _, isInt := discriminant.(int); isInt == isString {
+			return types.Error(fmt.Sprintf("Enum %q cannot mix int and string discriminants", enumDec.Name), member.Discriminant)
+		}
+		if other, exists := seen[discriminant]; exists {
+			return types.Error(fmt.Sprintf("Members %q and %q of enum %q have the same discriminant", other, member.Name, enumDec.Name), enumDec)
+		}
+		seen[discriminant] = member.Name
+
+		if n, isInt := discriminant.(int); isInt {
+			next = n + 1
+		}
+		enum.Types[member.Name].Discriminant = discriminant
+	}
+
+	return nil
+}
+
 func typeCheckEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.ModuleManager) types.ValidType {
 	declaredType := manager.SymbolTable.GetType(enumDec.Name)
 
-	for name, member := range enumDec.Members {
+	for _, member := range enumDec.Members {
+		name := member.Name
 		var dataType types.ValidType
 		if member.Types != nil {
 			if len(member.Types) == 1 {
@@ -697,23 +1060,28 @@ func typeCheckEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Mod
 				}
 			}
 		} else if member.StructMembers != nil {
-			structMembers := map[string]types.StructField{}
-			for name, field := range member.StructMembers {
+			structMembers := []types.StructField{}
+			for _, field := range member.StructMembers {
 				fieldType := TypeCheckType(field.Type, manager)
 				if fieldType.String() == "TypeError" {
 					return fieldType
 				}
-				structMembers[name] = types.StructField{
+				structMembers = append(structMembers, types.StructField{
+					Name:     field.Name,
 					Type:     fieldType,
 					Exported: field.Exported,
-				}
+				})
 			}
 			dataType = &types.Struct{
 				Name:    name,
 				Members: structMembers,
 			}
 		} else {
-			dataType = types.MakeUnitStruct(name)
+			unitType := types.MakeUnitStruct(name)
+			if enum, ok := declaredType.(*types.Enum); ok {
+				unitType.Enum = enum
+			}
+			dataType = unitType
 		}
 		if enumDec.IsUnion {
 			manager.SymbolTable.UpdateType(name, dataType)
@@ -725,3 +1093,47 @@ func typeCheckEnumDeclaration(enumDec *ast.EnumDeclaration, manager *modules.Mod
 
 	return declaredType
 }
+
+func typeCheckSelectStatement(selectStmt *ast.SelectStatement, manager *modules.ModuleManager) types.ValidType {
+	for _, selectCase := range selectStmt.Cases {
+		resultType := typeCheckExpression(selectCase.Operation, manager)
+		if resultType.String() == "TypeError" {
+			return resultType
+		}
+
+		newScope := symbols.NewChild(manager.SymbolTable, symbols.CONDITIONAL_SCOPE)
+		manager.EnterScope(newScope)
+
+		if selectCase.Variable != "" {
+			err := manager.SymbolTable.RegisterSymbol(selectCase.Variable, resultType, false)
+			if err != nil {
+				err.Line = selectCase.Operation.GetToken().Line
+				err.Column = selectCase.Operation.GetToken().Column
+				return err
+			}
+		}
+
+		for _, statement := range selectCase.Body {
+			err := typeCheckStatement(statement, manager)
+			if err.String() == "TypeError" {
+				return err
+			}
+		}
+		manager.ExitScope()
+	}
+
+	if selectStmt.Default != nil {
+		newScope := symbols.NewChild(manager.SymbolTable, symbols.CONDITIONAL_SCOPE)
+		manager.EnterScope(newScope)
+
+		for _, statement := range selectStmt.Default {
+			err := typeCheckStatement(statement, manager)
+			if err.String() == "TypeError" {
+				return err
+			}
+		}
+		manager.ExitScope()
+	}
+
+	return &types.Void{}
+}
@@ -0,0 +1,29 @@
+package typechecker
+
+import "testing"
+
+func TestDuplicateMethod(t *testing.T) {
+	source := `
+		struct V { x: int }
+		fn (V) f(): int { return 1 }
+		fn (V) f(): int { return 2 }`
+	if typeCheckSource(t, source) == nil {
+		t.Error("expected a type error declaring a method twice")
+	}
+}
+
+func TestInterfaceMethodsOnlyApplyToImplementingTypes(t *testing.T) {
+	declarations := `
+		interface Named { name(): string }
+		fn (Named) greet(): string { return "hi " + this.name() }
+		struct A { x: int }
+		fn (A) name(): string { return "a" }
+		struct B { x: int }`
+
+	if err := typeCheckSource(t, declarations+"\nprint(A { x: 1 }.greet())"); err != nil {
+		t.Error(err)
+	}
+	if typeCheckSource(t, declarations+"\nprint(B { x: 1 }.greet())") == nil {
+		t.Error("expected a type error calling an interface's method on a type without its members")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 
 	"github.com/gearsdatapacks/libra/errors"
-	"github.com/gearsdatapacks/libra/type_checker/registry"
 	"github.com/gearsdatapacks/libra/type_checker/types"
 )
 
@@ -20,8 +19,10 @@ const (
 )
 
 type SymbolTable struct {
-	Parent               *SymbolTable
-	variables            map[string]types.ValidType
+	Parent    *SymbolTable
+	variables map[string]types.ValidType
+	// Variables from this or outer scopes whose type is known to be more specific here
+	narrowed             map[string]types.ValidType
 	types                map[string]types.ValidType
 	kind                 scopeKind
 	returnType           types.ValidType
@@ -34,6 +35,7 @@ func New() *SymbolTable {
 	return &SymbolTable{
 		Parent:    nil,
 		variables: map[string]types.ValidType{},
+		narrowed:  map[string]types.ValidType{},
 		types:     map[string]types.ValidType{},
 		kind:      GLOBAL_SCOPE,
 		Exports:   map[string]types.ValidType{},
@@ -47,6 +49,7 @@ func NewChild(parent *SymbolTable, kind scopeKind) *SymbolTable {
 	return &SymbolTable{
 		Parent:    parent,
 		variables: map[string]types.ValidType{},
+		narrowed:  map[string]types.ValidType{},
 		types:     map[string]types.ValidType{},
 		kind:      kind,
 	}
@@ -63,10 +66,6 @@ func (st *SymbolTable) RegisterSymbol(name string, dataType types.ValidType, con
 		return types.Error(fmt.Sprintf("Cannot redeclare variable %q, it is already defined", name))
 	}
 
-	if _, ok := registry.Builtins[name]; ok {
-		return types.Error(fmt.Sprintf("Cannot redefine builtin function %q", name))
-	}
-
 	if array, isArray := dataType.(*types.ArrayLiteral); isArray {
 		array.CanInfer = false
 	}
@@ -88,6 +87,20 @@ func (st *SymbolTable) RegisterSymbol(name string, dataType types.ValidType, con
 }
 
 func (st *SymbolTable) GetSymbol(name string) types.ValidType {
+	for table := st; table != nil; table = table.Parent {
+		if dataType, ok := table.narrowed[name]; ok {
+			return dataType
+		}
+		if dataType, ok := table.variables[name]; ok {
+			return dataType
+		}
+	}
+
+	return types.Error(fmt.Sprintf("Variable %q is undefined", name))
+}
+
+// Like GetSymbol, but ignores narrowing, giving the type the variable was declared with
+func (st *SymbolTable) DeclaredSymbol(name string) types.ValidType {
 	table := st.resolveVariable(name)
 
 	if table == nil {
@@ -97,6 +110,41 @@ func (st *SymbolTable) GetSymbol(name string) types.ValidType {
 	return table.variables[name]
 }
 
+func (st *SymbolTable) Narrow(name string, dataType types.ValidType) {
+	st.narrowed[name] = dataType
+}
+
+// Forgets what is known about a variable, for example because it has been reassigned
+func (st *SymbolTable) ResetNarrowing(name string) {
+	for table := st; table != nil; table = table.Parent {
+		delete(table.narrowed, name)
+		if _, ok := table.variables[name]; ok {
+			return
+		}
+	}
+}
+
+// Forgets what is known about variables a function call could reassign,
+// which are the mutable ones declared outside the current function
+func (st *SymbolTable) ResetNonLocalNarrowing() {
+	local := map[*SymbolTable]bool{}
+	for table := st; table != nil && table.kind != GLOBAL_SCOPE; table = table.Parent {
+		local[table] = true
+		if table.kind == FUNCTION_SCOPE {
+			break
+		}
+	}
+
+	for table := st; table != nil; table = table.Parent {
+		for name := range table.narrowed {
+			declaredIn := table.resolveVariable(name)
+			if declaredIn != nil && !local[declaredIn] && !declaredIn.variables[name].Constant() {
+				delete(table.narrowed, name)
+			}
+		}
+	}
+}
+
 func (st *SymbolTable) Exists(name string) bool {
 	table := st.resolveVariable(name)
 
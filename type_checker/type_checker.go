@@ -163,6 +163,18 @@ func typeCheckFunctions(manager *modules.ModuleManager) error {
 		}
 	}
 
+	// Implementations are checked once every method is known
+	for _, file := range manager.Files {
+		for _, stmt := range file.Ast.Body {
+			if impl, ok := stmt.(*ast.ImplDeclaration); ok {
+				nextType := typeCheckImplParams(impl, manager)
+				if nextType.String() == "TypeError" {
+					return nextType.(*types.TypeError)
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -267,7 +279,16 @@ func FromAst(node ast.TypeExpression, table *symbols.SymbolTable) types.ValidTyp
 			return resultType
 		}
 
-		return &types.ErrorType{ResultType: resultType}
+		errorType := &types.ErrorType{ResultType: resultType}
+		for _, errorTypeExpr := range typeExpr.ErrorTypes {
+			nextType := FromAst(errorTypeExpr, table)
+			if nextType.String() == "TypeError" {
+				return nextType
+			}
+			errorType.ErrorTypes = append(errorType.ErrorTypes, nextType)
+		}
+
+		return errorType
 
 	case *ast.TupleType:
 		members := []types.ValidType{}
@@ -281,6 +302,30 @@ func FromAst(node ast.TypeExpression, table *symbols.SymbolTable) types.ValidTyp
 
 		return &types.Tuple{Members: members}
 
+	case *ast.OptionalType:
+		dataType := FromAst(typeExpr.DataType, table)
+		if dataType.String() == "TypeError" {
+			return dataType
+		}
+
+		return types.MakeOptional(dataType)
+
+	case *ast.PointerType:
+		dataType := FromAst(typeExpr.DataType, table)
+		if dataType.String() == "TypeError" {
+			return dataType
+		}
+
+		return &types.Pointer{DataType: dataType, Const: typeExpr.Const}
+
+	case *ast.ChannelType:
+		elemType := FromAst(typeExpr.ElemType, table)
+		if elemType.String() == "TypeError" {
+			return elemType
+		}
+
+		return &types.Channel{ElemType: elemType}
+
 	case *ast.VoidType:
 		return &types.Void{}
 
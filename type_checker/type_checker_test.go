@@ -0,0 +1,42 @@
+package typechecker
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gearsdatapacks/libra/interpreter/environment"
+	"github.com/gearsdatapacks/libra/lexer"
+	"github.com/gearsdatapacks/libra/modules"
+	"github.com/gearsdatapacks/libra/parser"
+	"github.com/gearsdatapacks/libra/type_checker/registry"
+	"github.com/gearsdatapacks/libra/type_checker/symbols"
+)
+
+func TestMain(m *testing.M) {
+	registry.Register()
+	os.Exit(m.Run())
+}
+
+// Type checks a program, returning the manager so tests can look at the state it was left in
+func checkSource(t *testing.T, source string) (*modules.ModuleManager, error) {
+	t.Helper()
+
+	tokens, err := lexer.New([]byte(source)).Tokenise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := parser.New().Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := modules.NewDetatched(symbols.New(), environment.New())
+	manager.Files[0].Ast = program
+	return manager, TypeCheck(manager)
+}
+
+func typeCheckSource(t *testing.T, source string) error {
+	t.Helper()
+	_, err := checkSource(t, source)
+	return err
+}
@@ -7,6 +7,14 @@ func isA[T ValidType](v ValidType) bool {
 	return ok
 }
 
+// Renders a type which has a suffix added to it, bracketing it if the suffix would apply to only part of it
+func inner(v ValidType) string {
+	if fn, ok := v.(*Function); isA[*Union](v) || (ok && fn.ReturnType != nil) {
+		return fmt.Sprintf("(%s)", v.String())
+	}
+	return v.String()
+}
+
 type UntypedNumber struct {
 	BaseType
 	Default         ValidType
@@ -79,20 +87,25 @@ type StringLiteral struct{ BaseType }
 func (s *StringLiteral) String() string         { return "string" }
 func (s *StringLiteral) Valid(t ValidType) bool { return isA[*StringLiteral](t) }
 
+// Indexing a string gives the character at that position
+func (s *StringLiteral) IndexBy(dataType ValidType) ValidType {
+	if !(&IntLiteral{}).Valid(dataType) {
+		return nil
+	}
+	return &StringLiteral{}
+}
+
 type ListLiteral struct {
 	BaseType
 	ElemType ValidType
 }
 
 func (list *ListLiteral) String() string {
-	if isA[*Union](list.ElemType) {
-		return fmt.Sprintf("(%s)[]", list.ElemType.String())
-	}
-	return list.ElemType.String() + "[]"
+	return inner(list.ElemType) + "[]"
 }
 func (list *ListLiteral) Valid(t ValidType) bool {
 	if l, isList := t.(*ListLiteral); isList {
-		if isA[*Infer](l.ElemType) {
+		if isA[*Infer](l.ElemType) || isA[*Infer](list.ElemType) {
 			return true
 		}
 		return list.ElemType.Valid(l.ElemType) && l.ElemType.Valid(list.ElemType)
@@ -107,6 +120,11 @@ func (list *ListLiteral) Valid(t ValidType) bool {
 }
 
 func (list *ListLiteral) Infer(dataType ValidType) (ValidType, bool) {
+	// A list of any type can be inferred from any array, not just an array literal
+	if array, ok := dataType.(*ArrayLiteral); ok && isA[*Infer](list.ElemType) && !isA[*Infer](array.ElemType) {
+		return &ListLiteral{ElemType: array.ElemType}, true
+	}
+
 	if !list.Valid(dataType) {
 		return list, false
 	}
@@ -140,6 +158,24 @@ func (list *ListLiteral) IndexBy(dataType ValidType) ValidType {
 	return list.ElemType
 }
 
+// Gets the type of the elements of a list or array, converting untyped elements to real types
+func ElemTypeOf(dataType ValidType) (ValidType, bool) {
+	var elemType ValidType
+	switch ty := dataType.(type) {
+	case *ListLiteral:
+		elemType = ty.ElemType
+	case *ArrayLiteral:
+		elemType = ty.ElemType
+	default:
+		return nil, false
+	}
+
+	if pseudo, ok := elemType.(PseudoType); ok {
+		elemType = pseudo.ToReal()
+	}
+	return elemType, true
+}
+
 type ArrayLiteral struct {
 	BaseType
 	ElemType ValidType
@@ -152,10 +188,7 @@ func (array *ArrayLiteral) String() string {
 	if array.Length != -1 {
 		length = fmt.Sprint(array.Length)
 	}
-	if isA[*Union](array.ElemType) {
-		return fmt.Sprintf("(%s)[%s]", array.ElemType.String(), length)
-	}
-	return fmt.Sprintf("%s[%s]", array.ElemType.String(), length)
+	return fmt.Sprintf("%s[%s]", inner(array.ElemType), length)
 }
 
 func (array *ArrayLiteral) Valid(t ValidType) bool {
@@ -240,21 +273,83 @@ func (maplit *MapLiteral) IndexBy(dataType ValidType) ValidType {
 type Pointer struct {
 	BaseType
 	DataType ValidType
+	// Whether it points to a constant, so it can only be read through
+	Const bool
 }
 
 func (p *Pointer) String() string {
-	if isA[*Union](p.DataType) {
-		return fmt.Sprintf("(%s)*", p.DataType.String())
+	if p.Const {
+		return "const " + inner(p.DataType) + "*"
 	}
-	return p.DataType.String() + "*"
+	return inner(p.DataType) + "*"
 }
 
+// Values can be written through a pointer as well as read, so the types must match exactly
 func (p *Pointer) Valid(t ValidType) bool {
 	ptr, ok := t.(*Pointer)
 	if !ok {
 		return false
 	}
-	return p.DataType.Valid(ptr.DataType)
+	if ptr.Const && !p.Const {
+		return false
+	}
+	return p.DataType.Valid(ptr.DataType) && ptr.DataType.Valid(p.DataType)
+}
+
+func (p *Pointer) member(member string, moduleId int) ValidType {
+	return Member(p.DataType, member, false, moduleId)
+}
+
+type Optional struct {
+	BaseType
+	DataType ValidType
+}
+
+func MakeOptional(dataType ValidType) ValidType {
+	if isA[*Optional](dataType) || isA[*NullLiteral](dataType) {
+		return dataType
+	}
+	return &Optional{DataType: dataType}
+}
+
+func (o *Optional) String() string {
+	return inner(o.DataType) + "?"
+}
+
+func (o *Optional) Valid(t ValidType) bool {
+	switch other := t.(type) {
+	case *Optional:
+		return o.DataType.Valid(other.DataType)
+	case *NullLiteral:
+		return true
+	case *Union:
+		for _, member := range other.Types {
+			if !o.Valid(member) {
+				return false
+			}
+		}
+		return true
+	}
+	return o.DataType.Valid(t)
+}
+
+func (o *Optional) CanCastTo(t ValidType) bool { return CanCast(o.DataType, t) }
+
+type Channel struct {
+	BaseType
+	ElemType ValidType
+}
+
+func (c *Channel) String() string {
+	return fmt.Sprintf("chan[%s]", c.ElemType.String())
+}
+
+func (c *Channel) Valid(t ValidType) bool {
+	ch, ok := t.(*Channel)
+	if !ok {
+		return false
+	}
+	return c.ElemType.Valid(ch.ElemType) && ch.ElemType.Valid(c.ElemType)
 }
 
 type Void struct{ BaseType }
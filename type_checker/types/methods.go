@@ -0,0 +1,252 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// The functions declared on a type, by name
+type MethodTable map[string]*Function
+
+// The methods and associated functions of a type
+type methodSet struct {
+	methods    MethodTable
+	associated MethodTable
+	// The methods of interfaces and unions which apply to the type, by name.
+	// Each name is worked out the first time it's looked up, then kept up to date as methods are added
+	inherited map[string][]*Function
+}
+
+// Must be called with the lock held for writing
+func (set *methodSet) table(associated bool) MethodTable {
+	if set.methods == nil {
+		set.methods = MethodTable{}
+		set.associated = MethodTable{}
+	}
+	if associated {
+		return set.associated
+	}
+	return set.methods
+}
+
+// Nominal types each own their methods, as they are only compatible with themselves
+type ownMethods struct {
+	set methodSet
+}
+
+func (own *ownMethods) ownedMethods() *methodSet {
+	return &own.set
+}
+
+type methodOwner interface {
+	ownedMethods() *methodSet
+}
+
+// Other types can't own methods, so they share them with every type of the same structure
+type structuralMethods struct {
+	dataType ValidType
+	set      methodSet
+}
+
+// Grouped by name, so there is usually only one to compare against
+var structural = map[string][]*structuralMethods{}
+
+// Methods which can apply to types other than the one they're declared on,
+// such as those of interfaces and unions, in the order they were added
+type inheritedMethod struct {
+	name   string
+	method *Function
+}
+
+var inheritedMethods = []inheritedMethod{}
+
+// The types which have had inherited methods worked out, and so need updating when one is added
+type resolvedType struct {
+	dataType ValidType
+	set      *methodSet
+}
+
+var resolvedTypes = []resolvedType{}
+
+var methodsMu sync.RWMutex
+
+// Gives the methods of a type. Comparing types can look up methods, so this must be called without the lock held
+func methodsOf(dataType ValidType) *methodSet {
+	if owner, ok := dataType.(methodOwner); ok {
+		return owner.ownedMethods()
+	}
+
+	name := dataType.String()
+	checked := 0
+	for {
+		methodsMu.RLock()
+		entries := structural[name]
+		methodsMu.RUnlock()
+
+		for _, entry := range entries[checked:] {
+			if entry.dataType == dataType || (entry.dataType.Valid(dataType) && dataType.Valid(entry.dataType)) {
+				return &entry.set
+			}
+		}
+		checked = len(entries)
+
+		methodsMu.Lock()
+		// Another type with the same name could have been added while we were comparing
+		if len(structural[name]) == checked {
+			entry := &structuralMethods{dataType: dataType}
+			structural[name] = append(structural[name], entry)
+			methodsMu.Unlock()
+			return &entry.set
+		}
+		methodsMu.Unlock()
+	}
+}
+
+func lookupTable(dataType ValidType, associated bool, name string) *Function {
+	set := methodsOf(dataType)
+
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+	if associated {
+		return set.associated[name]
+	}
+	return set.methods[name]
+}
+
+// Whether methods of this type only apply to the type itself
+func isClosed(dataType ValidType) bool {
+	switch dataType.(type) {
+	case *Struct, *TupleStruct, *UnitStruct, *ExplicitType:
+		return true
+	}
+	return false
+}
+
+func AddMethod(name string, method *Function) *TypeError {
+	set := methodsOf(method.MethodOf)
+
+	methodsMu.Lock()
+	table := set.table(false)
+	if _, exists := table[name]; exists {
+		methodsMu.Unlock()
+		return Error(fmt.Sprintf("Type %q already has method %q", method.MethodOf, name))
+	}
+	table[name] = method
+
+	if isClosed(method.MethodOf) {
+		methodsMu.Unlock()
+		return nil
+	}
+	inheritedMethods = append(inheritedMethods, inheritedMethod{name: name, method: method})
+	resolved := resolvedTypes
+	methodsMu.Unlock()
+
+	// Types which have already looked up this name won't check for it again
+	for _, receiver := range resolved {
+		methodsMu.RLock()
+		_, lookedUp := receiver.set.inherited[name]
+		methodsMu.RUnlock()
+
+		if lookedUp && method.MethodOf.Valid(receiver.dataType) {
+			methodsMu.Lock()
+			receiver.set.inherited[name] = append(receiver.set.inherited[name], method)
+			methodsMu.Unlock()
+		}
+	}
+	return nil
+}
+
+// The methods of interfaces and unions with this name which apply to a type
+func inheritedCandidates(dataType ValidType, name string) []*Function {
+	set := methodsOf(dataType)
+
+	for {
+		methodsMu.RLock()
+		candidates, resolved := set.inherited[name]
+		added := inheritedMethods
+		methodsMu.RUnlock()
+		if resolved {
+			return candidates
+		}
+
+		// Checking whether they apply can look up methods, so it's done without the lock
+		for _, inherited := range added {
+			if inherited.name == name && inherited.method.MethodOf.Valid(dataType) {
+				candidates = append(candidates, inherited.method)
+			}
+		}
+
+		methodsMu.Lock()
+		// If a method was added in the meantime, it might have been missed
+		if len(inheritedMethods) == len(added) {
+			if set.inherited == nil {
+				set.inherited = map[string][]*Function{}
+				resolvedTypes = append(resolvedTypes, resolvedType{dataType: dataType, set: set})
+			}
+			if _, resolved := set.inherited[name]; !resolved {
+				set.inherited[name] = candidates
+			}
+			candidates = set.inherited[name]
+			methodsMu.Unlock()
+			return candidates
+		}
+		methodsMu.Unlock()
+	}
+}
+
+// Finds a method defined on a type, ignoring its other members
+func Method(methodOf ValidType, name string, moduleId int) *Function {
+	method, _ := getMethod(methodOf, name, moduleId)
+	return method
+}
+
+// The method or field declared on the type itself, ignoring methods it gets from interfaces or unions
+func DeclaredMember(dataType ValidType, name string, moduleId int) ValidType {
+	if method := lookupTable(dataType, false, name); method != nil {
+		return method
+	}
+
+	if hasMembers, ok := dataType.(hasMembers); ok {
+		return hasMembers.member(name, moduleId)
+	}
+	return nil
+}
+
+func getMethod(methodOf ValidType, name string, moduleId int) (*Function, *TypeError) {
+	if methodOf == nil {
+		return nil, nil
+	}
+
+	method := lookupTable(methodOf, false, name)
+	if method == nil {
+		candidates := inheritedCandidates(methodOf, name)
+		if len(candidates) > 1 {
+			return nil, Error(fmt.Sprintf("Method %q of type %q is ambiguous, it could be from %q or %q", name, methodOf, candidates[0].MethodOf, candidates[1].MethodOf))
+		}
+		if len(candidates) == 1 {
+			method = candidates[0]
+		}
+	}
+
+	if method == nil || (methodOf.IsForeign(moduleId) && !method.Exported) {
+		return nil, nil
+	}
+	return method, nil
+}
+
+// Functions called through a type (T.name) instead of on a value
+func AddAssociatedFunction(name string, fn *Function) {
+	set := methodsOf(fn.MethodOf)
+
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+	set.table(true)[name] = fn
+}
+
+func AssociatedFunction(of ValidType, name string, moduleId int) *Function {
+	fn := lookupTable(of, true, name)
+	if fn == nil || (of.IsForeign(moduleId) && !fn.Exported) {
+		return nil
+	}
+	return fn
+}
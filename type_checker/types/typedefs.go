@@ -27,6 +27,11 @@ func MakeUnion(types ...ValidType) ValidType {
 func (u *Union) Valid(dataType ValidType) bool {
 	union, isUnion := dataType.(*Union)
 
+	// An optional could be either its type or null
+	if optional, isOptional := dataType.(*Optional); isOptional {
+		return u.Valid(optional.DataType) && u.Valid(&NullLiteral{})
+	}
+
 	// If it's a union, we want to make sure all possible values it could be are contained within this one
 	if isUnion {
 		for _, unionType := range union.Types {
@@ -52,7 +57,7 @@ func (u *Union) String() string {
 	typeStrings := []string{}
 
 	for _, dataType := range u.Types {
-		typeStrings = append(typeStrings, dataType.String())
+		typeStrings = append(typeStrings, inner(dataType))
 	}
 
 	return strings.Join(typeStrings, " | ")
@@ -61,12 +66,19 @@ func (u *Union) String() string {
 type EnumMember struct {
 	DataType ValidType
 	Exported bool
+	// The int or string the member converts to and from with ->
+	Discriminant any
 }
 
 type Enum struct {
 	BaseType
+	ownMethods
 	Name  string
 	Types map[string]*EnumMember
+	// The names of the members, in the order they were declared
+	Order []string
+	// Either int or string, depending on the members' discriminants
+	DiscriminantType ValidType
 }
 
 func (e *Enum) Valid(dataType ValidType) bool {
@@ -92,6 +104,9 @@ func (e *Enum) String() string {
 func (e *Enum) member(name string, moduleId int) ValidType {
 	member, ok := e.Types[name]
 	if !ok {
+		if fn := AssociatedFunction(e, name, moduleId); fn != nil {
+			return fn
+		}
 		return nil
 	}
 	if moduleId != e.module && !member.Exported {
@@ -101,13 +116,35 @@ func (e *Enum) member(name string, moduleId int) ValidType {
 	return member.DataType
 }
 
+func (e *Enum) CanCastTo(t ValidType) bool   { return e.DiscriminantType.Valid(t) }
+func (e *Enum) CanCastFrom(t ValidType) bool { return e.DiscriminantType.Valid(t) }
+
+// Finds the member with the given discriminant
+func (e *Enum) FromDiscriminant(discriminant any) (string, bool) {
+	for _, name := range e.Order {
+		if e.Types[name].Discriminant == discriminant {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 type Function struct {
 	BaseType
-	Name       string
-	Parameters []ValidType
+	Name           string
+	Parameters     []ValidType
+	ParameterNames []string
+	// How many of the trailing parameters have default values, not counting a variadic one
+	Defaults int
+	// Whether the last parameter is a list which collects any extra arguments
+	Variadic   bool
 	ReturnType ValidType
 	MethodOf   ValidType
-	Exported   bool
+	// Set for methods declared as `fn (&T)`, which get a pointer to the value they're called on
+	PointerReceiver bool
+	Exported        bool
+	// Set for functions which must be given a type argument (f[T]), giving their type for that argument
+	Instantiate func(typeArgument ValidType) *Function
 }
 
 func (fn *Function) Valid(dataType ValidType) bool {
@@ -124,6 +161,10 @@ func (fn *Function) Valid(dataType ValidType) bool {
 		return false
 	}
 
+	if len(fn.Parameters) != len(otherFn.Parameters) {
+		return false
+	}
+
 	for i, param := range fn.Parameters {
 		if !param.Valid(otherFn.Parameters[i]) {
 			return false
@@ -134,7 +175,49 @@ func (fn *Function) Valid(dataType ValidType) bool {
 }
 
 func (fn *Function) String() string {
-	return "function"
+	// The plain `function` type doesn't say what it takes or returns
+	if fn.ReturnType == nil {
+		return "function"
+	}
+
+	result := "fn "
+	if fn.MethodOf != nil {
+		receiver := fn.MethodOf.String()
+		if fn.PointerReceiver {
+			receiver = "&" + receiver
+		}
+		result += fmt.Sprintf("(%s) ", receiver)
+	}
+	result += fn.Name + "("
+
+	required := len(fn.Parameters) - fn.Defaults
+	if fn.Variadic {
+		required--
+	}
+	for i, param := range fn.Parameters {
+		if i != 0 {
+			result += ", "
+		}
+		variadic := fn.Variadic && i == len(fn.Parameters)-1
+		if variadic {
+			result += "..."
+			param = param.(*ListLiteral).ElemType
+		}
+		if i < len(fn.ParameterNames) {
+			result += fn.ParameterNames[i] + ": "
+		}
+		result += param.String()
+		// The type doesn't know the value, only that there is one
+		if i >= required && !variadic {
+			result += " = ..."
+		}
+	}
+	result += ")"
+
+	if !isA[*Void](fn.ReturnType) {
+		result += ": " + fn.ReturnType.String()
+	}
+	return result
 }
 
 type Any struct{ BaseType }
@@ -149,14 +232,27 @@ func (a *Any) String() string {
 }
 
 type StructField struct {
+	Name     string
 	Type     ValidType
 	Exported bool
 }
 
 type Struct struct {
 	BaseType
-	Name    string
-	Members map[string]StructField
+	ownMethods
+	Name string
+	// In the order they were declared
+	Members []StructField
+}
+
+// Finds the field with the given name
+func (s *Struct) Field(name string) (StructField, bool) {
+	for _, field := range s.Members {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return StructField{}, false
 }
 
 func (s *Struct) Valid(dataType ValidType) bool {
@@ -164,9 +260,13 @@ func (s *Struct) Valid(dataType ValidType) bool {
 	if !isStruct || struc.Name != s.Name {
 		return false
 	}
+	// Checked first so that structs which contain themselves don't recurse forever
+	if struc == s {
+		return true
+	}
 
-	for name, field := range struc.Members {
-		member, hasMember := s.Members[name]
+	for _, field := range struc.Members {
+		member, hasMember := s.Field(field.Name)
 		if !hasMember || !member.Type.Valid(field.Type) {
 			return false
 		}
@@ -180,7 +280,7 @@ func (s *Struct) String() string {
 }
 
 func (s *Struct) member(member string, moduleId int) ValidType {
-	memberType, ok := s.Members[member]
+	memberType, ok := s.Field(member)
 	if !ok {
 		return nil
 	}
@@ -198,8 +298,11 @@ func (s *Struct) member(member string, moduleId int) ValidType {
 
 type UnitStruct struct {
 	BaseType
+	ownMethods
 	Name string
 	Id   int
+	// The enum this is a member of, if any
+	Enum *Enum
 }
 
 var unitStructId = 0
@@ -225,14 +328,45 @@ func (s *UnitStruct) String() string {
 	return s.Name
 }
 
+func (s *UnitStruct) CanCastTo(t ValidType) bool {
+	return s.Enum != nil && (s.Enum.Valid(t) || s.Enum.CanCastTo(t))
+}
+
 type Interface struct {
 	BaseType
+	ownMethods
 	Name    string
 	Members map[string]ValidType
+	// Interfaces whose members are included in this one
+	Embeds []*Interface
 }
 
-func (i *Interface) Valid(dataType ValidType) bool {
+// The interface's own members, along with those of any interfaces it embeds
+func (i *Interface) AllMembers() map[string]ValidType {
+	members := map[string]ValidType{}
+	for _, embedded := range i.Embeds {
+		for name, member := range embedded.AllMembers() {
+			members[name] = member
+		}
+	}
 	for name, member := range i.Members {
+		members[name] = member
+	}
+	return members
+}
+
+// Whether this interface embeds another, directly or through other embedded interfaces
+func (i *Interface) EmbedsInterface(other *Interface) bool {
+	for _, embedded := range i.Embeds {
+		if embedded == other || embedded.EmbedsInterface(other) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Interface) Valid(dataType ValidType) bool {
+	for name, member := range i.AllMembers() {
 
 		memberType := Member(dataType, name, false, 0)
 		if memberType == nil {
@@ -252,7 +386,7 @@ func (i *Interface) String() string {
 }
 
 func (i *Interface) member(member string, moduleId int) ValidType {
-	memberType := i.Members[member]
+	memberType := i.AllMembers()[member]
 	if i.constant && memberType != nil {
 		memberType.MarkConstant()
 	}
@@ -273,18 +407,67 @@ var ErrorInterface = &Interface{
 type ErrorType struct {
 	BaseType
 	ResultType ValidType
+	// The errors which can be returned, or nil if any error can be
+	ErrorTypes []ValidType
 }
 
 func (e *ErrorType) Valid(dataType ValidType) bool {
 	if err, ok := dataType.(*ErrorType); ok {
-		return e.ResultType.Valid(err.ResultType)
+		if !e.ResultType.Valid(err.ResultType) {
+			return false
+		}
+		if e.ErrorTypes == nil {
+			return true
+		}
+		if err.ErrorTypes == nil {
+			return false
+		}
+
+		for _, errorType := range err.ErrorTypes {
+			if !e.AllowsError(errorType) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return e.ResultType.Valid(dataType) || e.AllowsError(dataType)
+}
+
+// Whether a value of the given type can be returned as one of this type's errors
+func (e *ErrorType) AllowsError(dataType ValidType) bool {
+	if e.ErrorTypes == nil {
+		return ErrorInterface.Valid(dataType)
+	}
+
+	for _, errorType := range e.ErrorTypes {
+		if errorType.Valid(dataType) {
+			return true
+		}
 	}
+	return false
+}
 
-	return e.ResultType.Valid(dataType) || ErrorInterface.Valid(dataType)
+// The types of error that can be returned
+func (e *ErrorType) Errors() []ValidType {
+	if e.ErrorTypes == nil {
+		return []ValidType{ErrorInterface}
+	}
+	return e.ErrorTypes
 }
 
 func (e *ErrorType) String() string {
-	return e.ResultType.String() + "!"
+	result := inner(e.ResultType) + "!"
+
+	for i, errorType := range e.ErrorTypes {
+		if i == 0 {
+			result = strings.TrimSuffix(result, "!") + " ! "
+		} else {
+			result += " | "
+		}
+		result += errorType.String()
+	}
+	return result
 }
 
 type TypeError struct {
@@ -380,6 +563,7 @@ func (tuple *Tuple) numberMember(member string) ValidType {
 
 type TupleStruct struct {
 	BaseType
+	ownMethods
 	Name    string
 	Members []ValidType
 }
@@ -461,12 +645,20 @@ func (t *Type) SetModule(moduleId int) {
 	t.DataType.SetModule(moduleId)
 }
 
+func (t *Type) CanCastTo(to ValidType) bool {
+	return CanCast(t.DataType, to)
+}
+
 func (t *Type) member(name string, moduleId int) ValidType {
+	if fn := AssociatedFunction(t.DataType, name, moduleId); fn != nil {
+		return fn
+	}
 	return Member(t.DataType, name, false, moduleId)
 }
 
 type ExplicitType struct {
 	BaseType
+	ownMethods
 	Name     string
 	Id       int
 	DataType ValidType
@@ -96,6 +96,7 @@ var typeTable = map[string]ValidType{
 	"null":     &NullLiteral{},
 	"function": &Function{},
 	"string":   &StringLiteral{},
+	"error":    ErrorInterface,
 }
 
 type TypeTable interface {
@@ -112,7 +113,10 @@ func FromString(typeString string, table TypeTable) ValidType {
 }
 
 func Member(memberOf ValidType, name string, isNumberMember bool, moduleId int) ValidType {
-	method := getMethod(memberOf, name, moduleId)
+	method, err := getMethod(memberOf, name, moduleId)
+	if err != nil {
+		return err
+	}
 	if method != nil {
 		return method
 	}
@@ -132,35 +136,34 @@ func Member(memberOf ValidType, name string, isNumberMember bool, moduleId int)
 	return nil
 }
 
-var methods = map[string][]*Function{}
-
-func AddMethod(name string, method *Function) {
-	overloads, ok := methods[name]
-	if !ok {
-		methods[name] = []*Function{method}
-	}
-	overloads = append(overloads, method)
-	methods[name] = overloads
-}
-
-func getMethod(methodOf ValidType, name string, moduleId int) *Function {
-	overloads, ok := methods[name]
-	if !ok {
-		return nil
-	}
-
-	for _, overload := range overloads {
-		if overload.MethodOf.Valid(methodOf) {
-			if methodOf.IsForeign(moduleId) && !overload.Exported {
-				return nil
+// Whether a variable of this type can be declared without a value.
+// Types which accept null, such as optionals, are zeroed to null
+func HasZeroValue(dataType ValidType) bool {
+	switch ty := dataType.(type) {
+	case *IntLiteral, *FloatLiteral, *BoolLiteral, *StringLiteral, *ListLiteral, *MapLiteral:
+		return true
+	case *ArrayLiteral:
+		return ty.Length != -1 && HasZeroValue(ty.ElemType)
+	case *Tuple:
+		for _, member := range ty.Members {
+			if !HasZeroValue(member) {
+				return false
 			}
-			return overload
 		}
+		return true
 	}
 
-	return nil
+	return dataType.Valid(&NullLiteral{})
 }
 
 type PseudoType interface {
 	ToReal() ValidType
 }
+
+// The type a value of a pseudo type is stored as, such as int for an untyped integer
+func ToReal(dataType ValidType) ValidType {
+	if pseudo, ok := dataType.(PseudoType); ok {
+		return pseudo.ToReal()
+	}
+	return dataType
+}
@@ -15,6 +15,13 @@ func typeCheckUnaryOperation(unOp *ast.UnaryOperation, manager *modules.ModuleMa
 		return valueType
 	}
 
+	// A pointer can be written through long after a narrowing stops holding,
+	// so it points to whatever the variable was declared as
+	if ident, ok := unOp.Value.(*ast.Identifier); ok && unOp.Operator == "&" && manager.SymbolTable.Exists(ident.Symbol) {
+		valueType = manager.SymbolTable.DeclaredSymbol(ident.Symbol)
+		manager.SymbolTable.ResetNarrowing(ident.Symbol)
+	}
+
 	not_exit_error := types.Error(fmt.Sprintf("Operator %q does not exist", unOp.Operator), unOp)
 
 	if unOp.Operator == "?" {
@@ -22,9 +29,15 @@ func typeCheckUnaryOperation(unOp *ast.UnaryOperation, manager *modules.ModuleMa
 			if !manager.SymbolTable.IsInFunctionScope() {
 				return types.Error("Cannot use operator \"?\" outside of a function", unOp)
 			}
-			if _, ok := manager.SymbolTable.ReturnType().(*types.ErrorType); !ok {
+			returnType, ok := manager.SymbolTable.ReturnType().(*types.ErrorType)
+			if !ok {
 				return types.Error("Can only use operator \"?\" in a function that returns an error type", unOp)
 			}
+			for _, propagated := range errType.Errors() {
+				if !returnType.AllowsError(propagated) {
+					return types.Error(fmt.Sprintf("Cannot propagate error of type %q from a function returning %q", propagated, returnType), unOp)
+				}
+			}
 			return errType.ResultType
 		}
 		return not_exit_error
@@ -39,6 +52,9 @@ func typeCheckUnaryOperation(unOp *ast.UnaryOperation, manager *modules.ModuleMa
 	resultType := checkerFn(valueType, unOp.Postfix)
 
 	if resultType == nil {
+		if isOptional(valueType) {
+			return optionalError(valueType, fmt.Sprintf("using operator %q", unOp.Operator), unOp.Value)
+		}
 		return types.Error(fmt.Sprintf("Operator %q is not defined for type %q", unOp.Operator, valueType), unOp)
 	}
 
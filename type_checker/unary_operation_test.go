@@ -0,0 +1,47 @@
+package typechecker
+
+import "testing"
+
+func TestAddressOfUsesDeclaredType(t *testing.T) {
+	invalid := `
+		var v: int? = 1
+		if v != null { const p = &v; v = null; print(*p + 1) }`
+	if typeCheckSource(t, invalid) == nil {
+		t.Error("expected a type error using a pointer to a narrowed variable after it is reassigned")
+	}
+
+	valid := `
+		fn reset(p: int?*) { *p = null }
+		var v: int? = 1
+		if v != null { reset(&v); print(v ?? 0) }`
+	if err := typeCheckSource(t, valid); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPointerToConstant(t *testing.T) {
+	invalid := map[string]string{
+		"assignment": `
+			const c = 1
+			const p = &c
+			*p = 2`,
+		"mutable parameter": `
+			fn set(p: int*) { *p = 2 }
+			const c = 1
+			set(&c)`,
+	}
+	for name, source := range invalid {
+		if typeCheckSource(t, source) == nil {
+			t.Errorf("%s: expected a type error writing to a constant through a pointer", name)
+		}
+	}
+
+	valid := `
+		fn read(p: const int*): int { return *p }
+		const c = 1
+		var v: int = 2
+		print(read(&c) + read(&v))`
+	if err := typeCheckSource(t, valid); err != nil {
+		t.Error(err)
+	}
+}